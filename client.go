@@ -1,20 +1,20 @@
 package wl
 
 import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"github.com/pkg/errors"
 	"net"
 	"os"
-	"github.com/pkg/errors"
+	"path/filepath"
+	"runtime"
+	"strconv"
 	"sync"
-	"sync/atomic"
+	"syscall"
+	"time"
 )
 
-var atomicIDCounter uint32
-
-func GetNewID() ObjectID {
-	// subtraction of one required to start id at 0
-	return ObjectID(atomic.AddUint32(&atomicIDCounter, 1) - 1)
-}
-
 type ObjectID uint32
 
 func (oid ObjectID) ID() uint32 {
@@ -25,37 +25,803 @@ type Object interface {
 	ID() uint32
 }
 
+// dispatcher is implemented by generated interface types. Decode decodes an
+// event addressed to the object as soon as it is read, registering any
+// objects the event creates so the events following it can be routed, and
+// returns the function that hands the event to the listeners once it is
+// dispatched from its queue. EventFds reports how many file descriptors
+// accompany the event with the given opcode so they can be claimed from the
+// connection as the event is read.
+type dispatcher interface {
+	Decode(ev *Event) (func(), error)
+	EventFds(opcode uint16) int
+}
+
+// Proxy is the client side handle of a protocol object. It is embedded in
+// every generated interface type and ties the object to the connection its
+// requests are sent on. Its state is guarded by the mutex of the client once
+// the object has been registered.
+type Proxy struct {
+	ObjectID
+	client  *Client
+	version uint32
+	// listeners are replaced rather than modified, so the slice returned
+	// by Listeners can be ranged over without the mutex. handles holds
+	// the handle of each listener to remove it by.
+	listeners []interface{}
+	handles   []*listenerHandle
+	// subscribers are the channels the events of the object are
+	// published on.
+	subscribers []subscriber
+	// events is the subscription returned by Events.
+	events subscriber
+	// queue is the queue events for the object are routed to, the
+	// default queue of the client if nil.
+	queue   *EventQueue
+	invalid error
+	// destroyed is set once a destructor request has been sent.
+	destroyed bool
+	// wrapped is the proxy a wrapper made by WithQueue stands in for.
+	wrapped *Proxy
+}
+
+func (p *Proxy) proxy() *Proxy {
+	return p
+}
+
+// Client returns the connection the proxy belongs to.
+func (p *Proxy) Client() *Client {
+	return p.client
+}
+
+// Version returns the version of the interface the object was created
+// with, which limits the requests it may send and the events it receives.
+func (p *Proxy) Version() uint32 {
+	return p.version
+}
+
+// lock locks the client of a registered proxy, returning the function that
+// unlocks it again.
+func (p *Proxy) lock() func() {
+	if p.client == nil {
+		return func() {}
+	}
+	p.client.mutex.Lock()
+	return p.client.mutex.Unlock
+}
+
+// listenerHandle identifies a listener added to a proxy, as listeners need
+// not be comparable. It is not zero sized so every handle has a distinct
+// address.
+type listenerHandle struct {
+	_ byte
+}
+
+// AddListener adds a listener the events of the object are dispatched to,
+// after the listeners added before it, and returns the function that
+// removes it again. Generated types wrap it in a typed AddListener.
+func (p *Proxy) AddListener(listener interface{}) func() {
+	if p.wrapped != nil {
+		p = p.wrapped
+	}
+	h := &listenerHandle{}
+	defer p.lock()()
+	if p.destroyed {
+		return func() {}
+	}
+	p.listeners = append(p.listeners[:len(p.listeners):len(p.listeners)], listener)
+	p.handles = append(p.handles[:len(p.handles):len(p.handles)], h)
+	return func() {
+		defer p.lock()()
+		for i, other := range p.handles {
+			if other == h {
+				p.listeners = append(p.listeners[:i:i], p.listeners[i+1:]...)
+				p.handles = append(p.handles[:i:i], p.handles[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Listeners returns the listeners of the object in the order they were
+// added, none once the object has been destroyed.
+func (p *Proxy) Listeners() []interface{} {
+	defer p.lock()()
+	return p.listeners
+}
+
+// Err returns the error requests on the object fail with: ErrObjectDestroyed
+// once it has been destroyed, the reason it was invalidated otherwise, or
+// nil if it can still be used.
+func (p *Proxy) Err() error {
+	defer p.lock()()
+	return p.err()
+}
+
+func (p *Proxy) err() error {
+	if p.wrapped != nil {
+		return p.wrapped.err()
+	}
+	if p.destroyed {
+		return ErrObjectDestroyed
+	}
+	return p.invalid
+}
+
+// invalidate makes every later request on the object other than a
+// destructor fail with err.
+func (p *Proxy) invalidate(err error) {
+	defer p.lock()()
+	p.invalid = err
+}
+
+// Send sends a request on behalf of the proxy, failing if the object has
+// been destroyed or invalidated. An object the request creates is assigned
+// to the queue of the proxy.
+func (p *Proxy) Send(msg *Message) error {
+	return p.client.send(msg, func() error {
+		if err := p.err(); err != nil {
+			return err
+		}
+		if obj, ok := msg.newObj.(proxied); ok {
+			obj.proxy().queue = p.queue
+		}
+		return nil
+	})
+}
+
+// SendDestructor sends the destructor request msg on behalf of the proxy
+// and removes the proxy and its listeners from the connection. Destructors
+// are sent even if the object has been invalidated, so the server can
+// release it, and every later request fails with ErrObjectDestroyed. It is
+// the hook generated destructor requests are built on and not meant to be
+// called directly: msg is sent as is, whatever interface the object has.
+func (p *Proxy) SendDestructor(msg *Message) error {
+	return p.client.send(msg, func() error {
+		if p.wrapped != nil {
+			return errors.New("a queue wrapper cannot be destroyed, destroy the object it wraps")
+		}
+		if p.destroyed {
+			return ErrObjectDestroyed
+		}
+		p.destroyed = true
+		p.listeners = nil
+		p.handles = nil
+		p.closeSubscriptions()
+		p.client.destroyProxy(p.ObjectID)
+		return nil
+	})
+}
+
+// proxied is implemented by every generated interface type through its
+// embedded Proxy.
+type proxied interface {
+	Object
+	Interface() *Interface
+	proxy() *Proxy
+}
+
+// Client is a connection to a wayland server.
+//
+// Requests may be sent from any goroutine: each one is written whole under
+// the send mutex, which also covers allocating the ID of an object it
+// creates, so requests from different goroutines never interleave and new
+// IDs reach the server in order. The mutex guards the rest of the
+// connection state. It is taken after the send mutex but never held while
+// writing to or waiting for data from the server, or while listeners run,
+// so events keep being read while a write blocks.
+//
+// Events are routed to the EventQueue of the object they are addressed to
+// as they are read, the default queue unless the object was assigned
+// another one. Dispatch, DispatchQueue, their Pending variants and
+// Roundtrip may be called from several goroutines. Only one of them reads
+// from the socket at a time, on behalf of every queue, while the others
+// wait on cond for the read to finish, similar to libwayland's
+// wl_display_prepare_read and wl_display_read_events. The events of a queue
+// are dispatched in order, but when several goroutines dispatch the same
+// queue, listeners may run on any of them and concurrently with each other.
+// The events of wl_display itself go to an internal queue that every
+// dispatch drains first, like libwayland's display queue, so protocol
+// errors and deleted IDs are handled whichever queue is dispatched.
 type Client struct {
-	conn net.Conn
+	conn    net.Conn
 	display *Display
-	mutex *sync.Mutex
-	cond *sync.Cond
-	objects map[ObjectID]Object
+	mutex   *sync.Mutex
+	// sendMutex serializes writes to the socket.
+	sendMutex *sync.Mutex
+	cond      *sync.Cond
+	objects   map[ObjectID]Object
+	ids       idAllocator
+	err       error
+	// reading is set while a goroutine reads from the socket on behalf of
+	// every dispatcher, with readCtx the context it reads for. interrupted
+	// is set once its read deadline has been moved to cancel the read.
+	reading     bool
+	readCtx     context.Context
+	interrupted bool
+	// queue is the default event queue and displayQueue the queue of the
+	// wl_display events.
+	queue        *EventQueue
+	displayQueue *EventQueue
+	inBuf        []byte
+	in           []byte
+	oobBuf       []byte
+	fds          []int
 }
 
+// Connect connects to the wayland server. An inherited socket passed in
+// WAYLAND_SOCKET takes precedence and the variable is unset so it is not
+// passed on to child processes. Otherwise sockName is used, falling back
+// to WAYLAND_DISPLAY and then wayland-0.
 func (c *Client) Connect(sockName string) error {
-	// TODO(mde): Add  support for connecting to an open file descriptor
-	if sockName ==  "" {
+	if fdStr := os.Getenv("WAYLAND_SOCKET"); fdStr != "" {
+		os.Unsetenv("WAYLAND_SOCKET")
+		fd, err := strconv.Atoi(fdStr)
+		if err != nil || fd < 0 {
+			return errors.Errorf("invalid WAYLAND_SOCKET (%s)", fdStr)
+		}
+		syscall.CloseOnExec(fd)
+		return c.ConnectFd(uintptr(fd))
+	}
+	if sockName == "" {
 		sockName = os.Getenv("WAYLAND_DISPLAY")
 	}
 	if sockName == "" {
 		sockName = "wayland-0"
 	}
-	addr, err := net.ResolveUnixAddr("unix", sockName)
+	path, err := socketPath(sockName)
+	if err != nil {
+		return err
+	}
+	addr, err := net.ResolveUnixAddr("unix", path)
 	if err != nil {
-		return errors.Wrapf(err, "unable to resolve unix socket address (%s)", sockName)
+		return errors.Wrapf(err, "unable to resolve unix socket address (%s)", path)
 	}
-	c.conn, err = net.DialUnix("unix", nil, addr)
+	conn, err := net.DialUnix("unix", nil, addr)
 	if err != nil {
-		return errors.Wrapf(err, "unable to connect to wayland server at (%s)", sockName)
+		return errors.Wrapf(err, "unable to connect to wayland server at (%s)", path)
 	}
+	return c.ConnectConn(conn)
+}
+
+// RuntimeDirError is returned when a relative socket name has to be
+// resolved but XDG_RUNTIME_DIR is not set.
+type RuntimeDirError struct {
+	Name string
+}
 
-	c.display = &Display{ObjectID: GetNewID()}
+func (e *RuntimeDirError) Error() string {
+	return fmt.Sprintf("XDG_RUNTIME_DIR is not set, unable to resolve socket (%s)", e.Name)
+}
+
+// socketPath resolves a display name the way libwayland does: absolute
+// paths are used as they are and anything else names a socket in
+// XDG_RUNTIME_DIR.
+func socketPath(name string) (string, error) {
+	path := name
+	if !filepath.IsAbs(name) {
+		dir := os.Getenv("XDG_RUNTIME_DIR")
+		if dir == "" {
+			return "", &RuntimeDirError{Name: name}
+		}
+		path = filepath.Join(dir, name)
+	}
+	// the path has to fit in sun_path including its NUL terminator
+	if len(path) >= 108 {
+		return "", errors.Errorf("socket path (%s) is too long", path)
+	}
+	return path, nil
+}
+
+// ConnectFd connects over an already connected socket file descriptor. The
+// client takes ownership of fd.
+func (c *Client) ConnectFd(fd uintptr) error {
+	f := os.NewFile(fd, "wayland-socket")
+	defer f.Close()
+	conn, err := net.FileConn(f)
+	if err != nil {
+		return errors.Wrapf(err, "unable to use file descriptor (%d) as a connection", fd)
+	}
+	return c.ConnectConn(conn)
+}
+
+// ConnectConn uses conn as the transport to the server. File descriptor
+// arguments can only be exchanged if conn is a *net.UnixConn.
+func (c *Client) ConnectConn(conn net.Conn) error {
+	if conn == nil {
+		return errors.New("connection is nil")
+	}
+	c.init(conn)
+	return nil
+}
+
+func (c *Client) init(conn net.Conn) {
+	c.conn = conn
 	c.mutex = &sync.Mutex{}
-	c.cond = sync.NewCond(&sync.Mutex{})
+	c.sendMutex = &sync.Mutex{}
+	c.cond = sync.NewCond(c.mutex)
 	c.objects = make(map[ObjectID]Object)
-	c.objects[c.display.ObjectID] = c.display
+	c.ids = idAllocator{}
+	c.queue = &EventQueue{client: c}
+	c.displayQueue = &EventQueue{client: c}
+	c.display = &Display{}
+	c.NewProxy(c.display, 1)
+	c.display.AddListener(displayHandler{c})
+	c.inBuf = make([]byte, maxMessageSize)
+	c.oobBuf = make([]byte, syscall.CmsgSpace(maxFdsPerMessage*4))
+}
+
+// connected returns ErrNotConnected unless the client has been connected,
+// which sets up the state every other method relies on. A nil client is
+// one a proxy that was never registered belongs to.
+func (c *Client) connected() error {
+	if c == nil || c.conn == nil {
+		return ErrNotConnected
+	}
+	return nil
+}
+
+// Display returns the wl_display singleton of the connection, the root from
+// which every other object is created. The client adds the first listener
+// of the display itself, to recycle object IDs and record protocol errors.
+func (c *Client) Display() *Display {
+	return c.display
+}
+
+// NewProxy assigns obj a fresh client ID and the given interface version
+// and registers it with the connection so events addressed to it can be
+// dispatched. obj must be a generated interface type, such as one returned
+// by Interface.New. Generated requests creating objects use
+// Message.PutNewID instead, which allocates the ID as the request is sent.
+func (c *Client) NewProxy(obj Object, version uint32) error {
+	if err := c.connected(); err != nil {
+		return err
+	}
+	px, ok := obj.(proxied)
+	if !ok {
+		return errors.Errorf("%T is not a protocol object", obj)
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.newProxy(px, version)
+}
+
+func (c *Client) newProxy(obj proxied, version uint32) error {
+	id, err := c.ids.alloc()
+	if err != nil {
+		return err
+	}
+	p := obj.proxy()
+	p.ObjectID = id
+	p.client = c
+	p.version = version
+	c.objects[id] = obj
+	return nil
+}
+
+// AddServerProxy registers an object the server created under the ID it
+// already carries. Server created objects share the version and queue of
+// parent, the object whose event created them.
+func (c *Client) AddServerProxy(obj proxied, parent *Proxy) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	p := obj.proxy()
+	if p.ObjectID < serverIDStart {
+		return errors.Errorf("server created object with ID %d outside of the server range", p.ObjectID)
+	}
+	if obj, ok := c.objects[p.ObjectID]; ok {
+		if _, dead := obj.(*zombie); !dead {
+			return errors.Errorf("server created object with ID %d which is already in use", p.ObjectID)
+		}
+	}
+	p.client = c
+	p.version = parent.version
+	p.queue = parent.queue
+	c.objects[p.ObjectID] = obj
+	return nil
+}
 
+// lookup returns the object with the given ID.
+func (c *Client) lookup(id ObjectID) (Object, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	obj, ok := c.objects[id]
+	return obj, ok
+}
+
+// zombie stands in for a destroyed object until the server acknowledges
+// its destruction. Events still in flight to the object are dropped, but the
+// file descriptors they carry have to be claimed from the stream and closed,
+// which needs the fd counts of the destroyed object.
+type zombie struct {
+	ObjectID
+	eventFds func(opcode uint16) int
+}
+
+func (z *zombie) Decode(ev *Event) (func(), error) {
+	ev.closeFds()
+	return nil, nil
+}
+
+func (z *zombie) EventFds(opcode uint16) int {
+	return z.eventFds(opcode)
+}
+
+// destroyProxy replaces the object with the given ID with a zombie until
+// the server deletes the ID. It is called with the mutex held.
+func (c *Client) destroyProxy(id ObjectID) {
+	d, ok := c.objects[id].(dispatcher)
+	if !ok {
+		delete(c.objects, id)
+		return
+	}
+	c.objects[id] = &zombie{ObjectID: id, eventFds: d.EventFds}
+}
+
+// deleteID forgets the object with the given ID once the server has
+// acknowledged its destruction, making the ID available for reuse. An
+// object the server destroyed on its own, such as a wl_callback, fails
+// further requests, and its subscriptions are closed once the events it
+// received before are dispatched from its queue.
+func (c *Client) deleteID(id ObjectID) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if obj, ok := c.objects[id].(proxied); ok {
+		p := obj.proxy()
+		p.destroyed = true
+		q := p.queue
+		if q == nil {
+			q = c.queue
+		}
+		q.events = append(q.events, func() {
+			defer p.lock()()
+			p.closeSubscriptions()
+		})
+		c.cond.Broadcast()
+	}
+	delete(c.objects, id)
+	c.ids.release(id)
+}
+
+// displayHandler is the listener the client installs on wl_display to
+// track the lifetime of object IDs.
+type displayHandler struct {
+	c *Client
+}
+
+func (h displayHandler) Error(objectID Object, code uint32, message string) {
+	perr := &ProtocolError{
+		Interface: "unknown",
+		Code:      code,
+		Message:   message,
+	}
+	if objectID != nil {
+		perr.ObjectID = ObjectID(objectID.ID())
+	}
+	if obj, ok := objectID.(proxied); ok {
+		iface := obj.Interface()
+		perr.Interface = iface.Name
+		perr.Name = iface.Errors[code]
+	}
+	h.c.mutex.Lock()
+	h.c.err = perr
+	h.c.cond.Broadcast()
+	h.c.mutex.Unlock()
+}
+
+func (h displayHandler) DeleteID(id uint32) {
+	h.c.deleteID(ObjectID(id))
+}
+
+// Send writes a fully marshaled request to the server, passing any file
+// descriptor arguments as SCM_RIGHTS ancillary data.
+func (c *Client) Send(msg *Message) error {
+	return c.send(msg, nil)
+}
+
+// send writes msg under the send mutex. With the mutex held it first runs
+// check, if any, which may update the state of the sending proxy, and
+// registers the object msg creates under a fresh ID.
+func (c *Client) send(msg *Message, check func() error) error {
+	if err := c.connected(); err != nil {
+		return err
+	}
+	uc, isUnix := c.conn.(*net.UnixConn)
+	if len(msg.files) > 0 && !isUnix {
+		return errors.New("file descriptors can only be sent over a unix socket")
+	}
+	c.sendMutex.Lock()
+	defer c.sendMutex.Unlock()
+	data, err := c.prepare(msg, check)
+	if err != nil {
+		return err
+	}
+	if len(msg.files) == 0 {
+		_, err = c.conn.Write(data)
+	} else {
+		_, _, err = uc.WriteMsgUnix(data, syscall.UnixRights(msg.fds()...), nil)
+		runtime.KeepAlive(msg.files)
+	}
+	if err != nil {
+		// the object the request created is registered and the server may
+		// have received part of the message, so the connection is out of
+		// sync and cannot be used any further
+		err = errors.Wrap(err, "unable to write message")
+		c.mutex.Lock()
+		if c.err == nil {
+			c.err = err
+		}
+		c.cond.Broadcast()
+		c.mutex.Unlock()
+	}
+	return err
+}
+
+// prepare does the part of send that needs the mutex, returning the bytes
+// to write. msg is validated before check runs, so a request that cannot
+// be sent leaves the state of the proxy and the connection alone.
+func (c *Client) prepare(msg *Message, check func() error) ([]byte, error) {
+	if len(msg.buf) > maxMessageSize {
+		return nil, errors.Errorf("message size (%d) exceeds maximum of %d bytes", len(msg.buf), maxMessageSize)
+	}
+	var obj proxied
+	if msg.newObj != nil {
+		var ok bool
+		if obj, ok = msg.newObj.(proxied); !ok {
+			return nil, errors.Errorf("%T is not a protocol object", msg.newObj)
+		}
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if check != nil {
+		if err := check(); err != nil {
+			return nil, err
+		}
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	if obj != nil {
+		if err := c.newProxy(obj, msg.newVersion); err != nil {
+			return nil, err
+		}
+		binary.NativeEndian.PutUint32(msg.buf[msg.newOff:], uint32(obj.proxy().ObjectID))
+	}
+	return msg.bytes(), nil
+}
+
+// Dispatch blocks until at least one event is waiting on the default queue
+// or for the wl_display, reading from the server if there is none, and then
+// dispatches every event on the queue to the listeners of the objects they
+// are addressed to. It also returns once another goroutine has dispatched events from the
+// queue while it was waiting, as those may be the ones the caller waits
+// for. After a wl_display.error event it returns the *ProtocolError.
+func (c *Client) Dispatch() error {
+	return c.dispatchQueue(context.Background(), c.queue)
+}
+
+// DispatchPending dispatches the events already waiting on the default
+// queue without blocking to read more.
+func (c *Client) DispatchPending() error {
+	return c.DispatchQueuePending(c.queue)
+}
+
+func (c *Client) dispatchQueue(ctx context.Context, q *EventQueue) error {
+	if err := c.connected(); err != nil {
+		return err
+	}
+	if q.client != c {
+		return errors.New("event queue belongs to another client")
+	}
+	c.mutex.Lock()
+	start := q.dispatched
+	for {
+		if c.err != nil {
+			c.mutex.Unlock()
+			return c.err
+		}
+		if len(q.events) > 0 || len(c.displayQueue.events) > 0 {
+			break
+		}
+		if q.dispatched != start {
+			c.mutex.Unlock()
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			c.mutex.Unlock()
+			return err
+		}
+		if c.reading {
+			c.cond.Wait()
+			continue
+		}
+		if err := c.readEvents(ctx); err != nil {
+			c.mutex.Unlock()
+			return err
+		}
+	}
+	c.mutex.Unlock()
+	return c.DispatchQueuePending(q)
+}
+
+// Roundtrip blocks until the server has processed every request sent so far
+// and all events they caused on the default queue have been dispatched. It
+// issues a wl_display.sync request and dispatches until the callback fires,
+// returning its serial. If ctx is done first its error is returned.
+func (c *Client) Roundtrip(ctx context.Context) (uint32, error) {
+	return c.RoundtripQueue(ctx, c.queue)
+}
+
+// dispatchUntil dispatches events from q until cond reports true, returning
+// the error of ctx if it is done first.
+func (c *Client) dispatchUntil(ctx context.Context, q *EventQueue, cond func() bool) error {
+	// unblock a read done on behalf of ctx, or the wait for the read of
+	// another goroutine, once ctx is done
+	stop := context.AfterFunc(ctx, func() {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		if c.reading && c.readCtx == ctx {
+			c.conn.SetReadDeadline(time.Now())
+			c.interrupted = true
+		}
+		c.cond.Broadcast()
+	})
+	defer stop()
+
+	for !cond() {
+		if err := c.dispatchQueue(ctx, q); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+	}
 	return nil
 }
 
+// syncListener records the wl_callback.done event of a Roundtrip. serial
+// may be read once fired is closed.
+type syncListener struct {
+	fired  chan struct{}
+	serial uint32
+}
+
+func (l *syncListener) Done(callbackData uint32) {
+	l.serial = callbackData
+	close(l.fired)
+}
+
+// readEvents performs a single read from the connection on behalf of every
+// queue, appending the data to any partial message left over from the
+// previous read, queueing any file descriptors received with it and routing
+// the complete events. It is called with the mutex held, which it releases
+// while waiting for data. An event that cannot be decoded fails the
+// connection.
+func (c *Client) readEvents(ctx context.Context) error {
+	c.reading = true
+	c.readCtx = ctx
+	c.mutex.Unlock()
+	n, fds, err := c.read()
+	c.mutex.Lock()
+	c.in = append(c.in, c.inBuf[:n]...)
+	c.fds = append(c.fds, fds...)
+	rerr := c.route()
+	if rerr != nil && c.err == nil {
+		// a malformed event leaves the client out of sync with the
+		// server, so like libwayland treat it as fatal
+		c.err = rerr
+	}
+	c.reading = false
+	c.readCtx = nil
+	if c.interrupted {
+		c.conn.SetReadDeadline(time.Time{})
+		c.interrupted = false
+	}
+	c.cond.Broadcast()
+	if err != nil {
+		return err
+	}
+	return rerr
+}
+
+// route decodes the complete events in the input buffer and appends them to
+// the queues of the objects they are addressed to. It is called by the
+// reading goroutine with the mutex held, which it releases while decoding
+// so the objects events create can be registered.
+func (c *Client) route() error {
+	for {
+		ev, d, err := c.nextEvent()
+		if err != nil || ev == nil {
+			return err
+		}
+		if d == nil {
+			// events racing with the destruction of their object are dropped
+			ev.closeFds()
+			continue
+		}
+		c.mutex.Unlock()
+		deliver, err := d.Decode(ev)
+		c.mutex.Lock()
+		if err != nil {
+			return errors.Wrapf(err, "unable to decode event for object %d", ev.Sender)
+		}
+		if deliver == nil {
+			continue
+		}
+		q := c.queue
+		if d == c.display {
+			q = c.displayQueue
+		} else if obj, ok := d.(proxied); ok && obj.proxy().queue != nil {
+			q = obj.proxy().queue
+		}
+		q.events = append(q.events, deliver)
+	}
+}
+
+// read reads from the socket into inBuf. Only the goroutine that set
+// reading may call it.
+func (c *Client) read() (int, []int, error) {
+	uc, ok := c.conn.(*net.UnixConn)
+	if !ok {
+		n, err := c.conn.Read(c.inBuf)
+		return n, nil, errors.Wrap(err, "unable to read from server")
+	}
+	n, oobn, _, _, err := uc.ReadMsgUnix(c.inBuf, c.oobBuf)
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "unable to read from server")
+	}
+	cmsgs, err := syscall.ParseSocketControlMessage(c.oobBuf[:oobn])
+	if err != nil {
+		return n, nil, errors.Wrap(err, "unable to parse ancillary data")
+	}
+	fds := make([]int, 0)
+	for i := range cmsgs {
+		rights, err := syscall.ParseUnixRights(&cmsgs[i])
+		if err != nil {
+			return n, fds, errors.Wrap(err, "unable to parse file descriptors")
+		}
+		fds = append(fds, rights...)
+	}
+	return n, fds, nil
+}
+
+// peekEvent reports whether a complete message is waiting in the input
+// buffer.
+func (c *Client) peekEvent() (bool, error) {
+	if len(c.in) < headerSize {
+		return false, nil
+	}
+	size := int(binary.NativeEndian.Uint32(c.in[4:]) >> 16)
+	if size < headerSize || size > maxMessageSize || size%4 != 0 {
+		return false, errors.Errorf("invalid message size (%d) from server", size)
+	}
+	return len(c.in) >= size, nil
+}
+
+// nextEvent removes the next complete message from the input buffer along
+// with its file descriptors, returning it with the object it is addressed
+// to, or nil if there is none. It is called with the mutex held.
+func (c *Client) nextEvent() (*Event, dispatcher, error) {
+	ok, err := c.peekEvent()
+	if !ok || err != nil {
+		return nil, nil, err
+	}
+	word := binary.NativeEndian.Uint32(c.in[4:])
+	size := int(word >> 16)
+	ev := &Event{
+		Sender: ObjectID(binary.NativeEndian.Uint32(c.in)),
+		Opcode: uint16(word),
+		data:   append([]byte(nil), c.in[headerSize:size]...),
+	}
+	c.in = c.in[size:]
+	d, ok := c.objects[ev.Sender].(dispatcher)
+	if ok {
+		n := d.EventFds(ev.Opcode)
+		if n > len(c.fds) {
+			return nil, nil, errors.Errorf("event %d on object %d is missing file descriptors", ev.Opcode, ev.Sender)
+		}
+		ev.fds = c.fds[:n:n]
+		c.fds = c.fds[n:]
+	}
+	return ev, d, nil
+}
@@ -0,0 +1,780 @@
+package wl
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// socketPair returns a connected client whose server end is returned for
+// the test to script.
+func socketPair(t *testing.T) (*Client, *net.UnixConn) {
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_STREAM|syscall.SOCK_CLOEXEC, 0)
+	require.NoError(t, err)
+	conns := make([]*net.UnixConn, 2)
+	for i, fd := range fds {
+		f := os.NewFile(uintptr(fd), "socketpair")
+		conn, err := net.FileConn(f)
+		require.NoError(t, err)
+		f.Close()
+		conns[i] = conn.(*net.UnixConn)
+	}
+	c := &Client{}
+	require.NoError(t, c.ConnectConn(conns[0]))
+	t.Cleanup(func() {
+		conns[0].Close()
+		conns[1].Close()
+	})
+	return c, conns[1]
+}
+
+type registryRecorder struct {
+	events  int
+	globals map[uint32]string
+}
+
+func (r *registryRecorder) Global(name uint32, iface string, version uint32) {
+	r.events++
+	r.globals[name] = iface
+}
+
+func (r *registryRecorder) GlobalRemove(name uint32) {
+	r.events++
+	delete(r.globals, name)
+}
+
+func TestDispatchRegistryGlobal(t *testing.T) {
+	c, server := socketPair(t)
+	registry, err := c.Display().GetRegistry()
+	require.NoError(t, err)
+	rec := &registryRecorder{globals: make(map[uint32]string)}
+	registry.AddListener(rec)
+
+	for i, iface := range []string{"wl_compositor", "wl_shm"} {
+		msg := NewMessage(registry.ObjectID, 0)
+		msg.PutUint(uint32(i + 1))
+		msg.PutString(iface)
+		msg.PutUint(1)
+		_, err = server.Write(msg.bytes())
+		require.NoError(t, err)
+	}
+	msg := NewMessage(registry.ObjectID, 1)
+	msg.PutUint(1)
+	_, err = server.Write(msg.bytes())
+	require.NoError(t, err)
+
+	for rec.events < 3 {
+		require.NoError(t, c.Dispatch())
+	}
+	assert.Equal(t, map[uint32]string{2: "wl_shm"}, rec.globals)
+}
+
+func TestMultipleListeners(t *testing.T) {
+	c, server := socketPair(t)
+	registry, err := c.Display().GetRegistry()
+	require.NoError(t, err)
+	var order []string
+	first := registry.AddListener(RegistryListenerFuncs{
+		OnGlobal: func(name uint32, iface string, version uint32) {
+			order = append(order, "first "+iface)
+		},
+	})
+	registry.AddListener(RegistryListenerFuncs{
+		OnGlobal: func(name uint32, iface string, version uint32) {
+			order = append(order, "second "+iface)
+		},
+	})
+	rec := &registryRecorder{globals: make(map[uint32]string)}
+	registry.AddListener(rec)
+
+	sendGlobal(t, server, registry, 1, "wl_seat", 5)
+	require.NoError(t, c.Dispatch())
+	assert.Equal(t, []string{"first wl_seat", "second wl_seat"}, order)
+
+	// events without a function are skipped
+	msg := NewMessage(registry.ObjectID, 1)
+	msg.PutUint(1)
+	_, err = server.Write(msg.bytes())
+	require.NoError(t, err)
+	require.NoError(t, c.Dispatch())
+	assert.Equal(t, 2, rec.events)
+
+	first()
+	first()
+	sendGlobal(t, server, registry, 2, "wl_shm", 1)
+	require.NoError(t, c.Dispatch())
+	assert.Equal(t, []string{"first wl_seat", "second wl_seat", "second wl_shm"}, order)
+	assert.Len(t, registry.Listeners(), 2)
+}
+
+type keymapRecorder struct {
+	KeyboardListener
+	fd *os.File
+}
+
+func (r *keymapRecorder) Keymap(format KeyboardKeymapFormat, fd *os.File, size uint32) {
+	r.fd = fd
+}
+
+func TestFdPassing(t *testing.T) {
+	c, server := socketPair(t)
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer r.Close()
+	defer w.Close()
+
+	shm := &Shm{}
+	require.NoError(t, c.NewProxy(shm, 1))
+	_, err = shm.CreatePool(w, 4096)
+	require.NoError(t, err)
+
+	buf := make([]byte, maxMessageSize)
+	oob := make([]byte, syscall.CmsgSpace(4))
+	_, oobn, _, _, err := server.ReadMsgUnix(buf, oob)
+	require.NoError(t, err)
+	cmsgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	require.NoError(t, err)
+	require.Len(t, cmsgs, 1)
+	fds, err := syscall.ParseUnixRights(&cmsgs[0])
+	require.NoError(t, err)
+	require.Len(t, fds, 1)
+
+	// hand the received descriptor back as a wl_keyboard.keymap event
+	keyboard := &Keyboard{}
+	require.NoError(t, c.NewProxy(keyboard, 1))
+	rec := &keymapRecorder{}
+	keyboard.AddListener(rec)
+	msg := NewMessage(keyboard.ObjectID, 0)
+	msg.PutUint(uint32(KeyboardKeymapFormatXkbV1))
+	msg.PutUint(4096)
+	_, _, err = server.WriteMsgUnix(msg.bytes(), syscall.UnixRights(fds...), nil)
+	require.NoError(t, err)
+	syscall.Close(fds[0])
+
+	require.NoError(t, c.Dispatch())
+	require.NotNil(t, rec.fd)
+	defer rec.fd.Close()
+	_, err = rec.fd.Write([]byte("keymap"))
+	require.NoError(t, err)
+	got := make([]byte, 6)
+	_, err = r.Read(got)
+	require.NoError(t, err)
+	assert.Equal(t, "keymap", string(got))
+}
+
+func TestSendFailure(t *testing.T) {
+	conn, server := net.Pipe()
+	defer conn.Close()
+	c := &Client{}
+	require.NoError(t, c.ConnectConn(conn))
+	drained := make(chan struct{})
+	go func() {
+		io.Copy(io.Discard, server)
+		close(drained)
+	}()
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer r.Close()
+	defer w.Close()
+
+	// a request that cannot be sent does not allocate its ID
+	shm := &Shm{}
+	require.NoError(t, c.NewProxy(shm, 1))
+	_, err = shm.CreatePool(w, 4096)
+	assert.Error(t, err)
+	assert.NotContains(t, c.objects, ObjectID(3))
+	registry, err := c.Display().GetRegistry()
+	require.NoError(t, err)
+	assert.Equal(t, ObjectID(3), registry.ObjectID)
+
+	// a failed write leaves the connection out of sync and fails it
+	server.Close()
+	<-drained
+	_, err = c.Display().Sync()
+	require.Error(t, err)
+	_, serr := c.Display().GetRegistry()
+	assert.Equal(t, err, serr)
+	assert.Equal(t, err, c.DispatchPending())
+}
+
+func TestObjectIDRecycling(t *testing.T) {
+	c, server := socketPair(t)
+	other, _ := socketPair(t)
+	assert.Equal(t, ObjectID(1), c.Display().ObjectID)
+	assert.Equal(t, ObjectID(1), other.Display().ObjectID)
+
+	cb, err := c.Display().Sync()
+	require.NoError(t, err)
+	assert.Equal(t, ObjectID(2), cb.ObjectID)
+
+	msg := NewMessage(c.Display().ObjectID, 1)
+	msg.PutUint(uint32(cb.ObjectID))
+	_, err = server.Write(msg.bytes())
+	require.NoError(t, err)
+	require.NoError(t, c.Dispatch())
+	assert.NotContains(t, c.objects, cb.ObjectID)
+
+	reg, err := c.Display().GetRegistry()
+	require.NoError(t, err)
+	assert.Equal(t, ObjectID(2), reg.ObjectID)
+}
+
+func TestDestructor(t *testing.T) {
+	c, server := socketPair(t)
+	keyboard := &Keyboard{}
+	require.NoError(t, c.NewProxy(keyboard, 3))
+	rec := &keymapRecorder{}
+	keyboard.AddListener(rec)
+	id := keyboard.ObjectID
+
+	require.NoError(t, keyboard.Release())
+	assert.Empty(t, keyboard.Listeners())
+	assert.IsType(t, &zombie{}, c.objects[id])
+	assert.Equal(t, ErrObjectDestroyed, keyboard.Release())
+	assert.Equal(t, ErrObjectDestroyed, keyboard.Proxy.Send(NewMessage(id, 0)))
+
+	// a keymap racing with the release is dropped and its descriptor
+	// closed, without the stream losing track of the delete_id after it
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer r.Close()
+	msg := NewMessage(id, 0)
+	msg.PutUint(uint32(KeyboardKeymapFormatXkbV1))
+	msg.PutUint(4096)
+	_, _, err = server.WriteMsgUnix(msg.bytes(), syscall.UnixRights(int(w.Fd())), nil)
+	require.NoError(t, err)
+	w.Close()
+	msg = NewMessage(c.Display().ObjectID, 1)
+	msg.PutUint(uint32(id))
+	_, err = server.Write(msg.bytes())
+	require.NoError(t, err)
+
+	for c.objects[id] != nil {
+		require.NoError(t, c.Dispatch())
+	}
+	assert.Nil(t, rec.fd)
+	require.NoError(t, r.SetReadDeadline(time.Now().Add(time.Second)))
+	_, err = r.Read(make([]byte, 1))
+	assert.Equal(t, io.EOF, err, "descriptor of the dropped event was not closed")
+}
+
+func TestCreateOnDestroyed(t *testing.T) {
+	c, _ := socketPair(t)
+	pool := &ShmPool{}
+	require.NoError(t, c.NewProxy(pool, 1))
+	require.NoError(t, pool.Destroy())
+	next := c.ids.next
+	_, err := pool.CreateBuffer(0, 1, 1, 4, ShmFormatArgb8888)
+	assert.Equal(t, ErrObjectDestroyed, err)
+	assert.Equal(t, next, c.ids.next, "no ID must be allocated for the new object")
+}
+
+func TestConnectWaylandSocket(t *testing.T) {
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_STREAM, 0)
+	require.NoError(t, err)
+	server := os.NewFile(uintptr(fds[1]), "server")
+	defer server.Close()
+	t.Setenv("WAYLAND_SOCKET", strconv.Itoa(fds[0]))
+	t.Setenv("XDG_RUNTIME_DIR", "")
+
+	c := &Client{}
+	require.NoError(t, c.Connect("does-not-exist"))
+	defer c.conn.Close()
+	_, ok := os.LookupEnv("WAYLAND_SOCKET")
+	assert.False(t, ok)
+
+	_, err = c.Display().GetRegistry()
+	require.NoError(t, err)
+	buf := make([]byte, 64)
+	n, err := server.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, 12, n)
+}
+
+func TestConnectRuntimeDir(t *testing.T) {
+	dir := t.TempDir()
+	l, err := net.Listen("unix", filepath.Join(dir, "wayland-test"))
+	require.NoError(t, err)
+	defer l.Close()
+	t.Setenv("WAYLAND_SOCKET", "")
+	t.Setenv("XDG_RUNTIME_DIR", dir)
+	c := &Client{}
+	require.NoError(t, c.Connect("wayland-test"))
+	c.conn.Close()
+
+	t.Setenv("WAYLAND_DISPLAY", "wayland-test")
+	c = &Client{}
+	require.NoError(t, c.Connect(""))
+	c.conn.Close()
+
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	c = &Client{}
+	err = c.Connect("wayland-test")
+	var rdErr *RuntimeDirError
+	require.True(t, errors.As(err, &rdErr))
+	assert.Equal(t, "wayland-test", rdErr.Name)
+
+	c = &Client{}
+	require.NoError(t, c.Connect(filepath.Join(dir, "wayland-test")))
+	c.conn.Close()
+}
+
+func TestProtocolError(t *testing.T) {
+	c, server := socketPair(t)
+	shm := &Shm{}
+	require.NoError(t, c.NewProxy(shm, 1))
+
+	msg := NewMessage(c.Display().ObjectID, 0)
+	msg.PutObject(shm.ObjectID)
+	msg.PutUint(uint32(ShmErrorInvalidStride))
+	msg.PutString("invalid stride")
+	_, err := server.Write(msg.bytes())
+	require.NoError(t, err)
+
+	err = c.Dispatch()
+	var perr *ProtocolError
+	require.True(t, errors.As(err, &perr))
+	assert.Equal(t, shm.ObjectID, perr.ObjectID)
+	assert.Equal(t, "wl_shm", perr.Interface)
+	assert.Equal(t, uint32(ShmErrorInvalidStride), perr.Code)
+	assert.Equal(t, "ShmErrorInvalidStride", perr.Name)
+	assert.Equal(t, "invalid stride", perr.Message)
+
+	_, err = c.Display().GetRegistry()
+	assert.Equal(t, perr, err)
+	assert.Equal(t, perr, c.Dispatch())
+}
+
+func TestNotConnected(t *testing.T) {
+	c := &Client{}
+	assert.Equal(t, ErrNotConnected, c.Dispatch())
+	assert.Equal(t, ErrNotConnected, c.DispatchPending())
+	assert.Equal(t, ErrNotConnected, c.DispatchQueue(c.NewEventQueue()))
+	_, err := c.Roundtrip(context.Background())
+	assert.Equal(t, ErrNotConnected, err)
+	assert.Equal(t, ErrNotConnected, c.Send(NewMessage(1, 0)))
+	assert.Equal(t, ErrNotConnected, c.NewProxy(&Registry{}, 1))
+
+	// objects never registered with a client cannot send requests
+	_, err = (&Compositor{}).CreateSurface()
+	assert.Equal(t, ErrNotConnected, err)
+}
+
+func TestMalformedEvent(t *testing.T) {
+	c, server := socketPair(t)
+	registry, err := c.Display().GetRegistry()
+	require.NoError(t, err)
+	rec := &registryRecorder{globals: make(map[uint32]string)}
+	registry.AddListener(rec)
+
+	bad := NewMessage(registry.ObjectID, 0)
+	bad.PutUint(1)
+	bad.PutNullString(nil)
+	bad.PutUint(1)
+	good := NewMessage(registry.ObjectID, 1)
+	good.PutUint(1)
+	_, err = server.Write(append(bad.bytes(), good.bytes()...))
+	require.NoError(t, err)
+
+	// the connection fails instead of waiting on events behind the bad one
+	err = c.Dispatch()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "null string")
+	assert.Equal(t, err, c.Dispatch())
+	_, rerr := c.Roundtrip(context.Background())
+	assert.Equal(t, err, rerr)
+	_, rerr = c.Display().GetRegistry()
+	assert.Equal(t, err, rerr)
+	assert.Equal(t, 0, rec.events)
+}
+
+func TestRoundtrip(t *testing.T) {
+	c, server := socketPair(t)
+	go func() {
+		buf := make([]byte, 12)
+		if _, err := io.ReadFull(server, buf); err != nil {
+			return
+		}
+		msg := NewMessage(ObjectID(binary.NativeEndian.Uint32(buf[8:])), 0)
+		msg.PutUint(42)
+		server.Write(msg.bytes())
+	}()
+	serial, err := c.Roundtrip(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint32(42), serial)
+}
+
+func TestRoundtripCancel(t *testing.T) {
+	c, _ := socketPair(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := c.Roundtrip(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	// the connection remains usable after the cancelled roundtrip
+	_, err = c.Display().GetRegistry()
+	assert.NoError(t, err)
+}
+
+func TestRoundtripCancelWhileOtherReads(t *testing.T) {
+	c, server := socketPair(t)
+	registry, err := c.Display().GetRegistry()
+	require.NoError(t, err)
+	dispatched := make(chan error, 1)
+	go func() {
+		dispatched <- c.Dispatch()
+	}()
+	for {
+		c.mutex.Lock()
+		reading := c.reading
+		c.mutex.Unlock()
+		if reading {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// the cancelled roundtrip waits for the read of the other goroutine
+	// and must not cut it short
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = c.Roundtrip(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	select {
+	case err := <-dispatched:
+		t.Fatalf("dispatch returned early: %v", err)
+	default:
+	}
+
+	msg := NewMessage(registry.ObjectID, 1)
+	msg.PutUint(1)
+	_, err = server.Write(msg.bytes())
+	require.NoError(t, err)
+	assert.NoError(t, <-dispatched)
+}
+
+func TestBind(t *testing.T) {
+	c, server := socketPair(t)
+	registry, err := c.Display().GetRegistry()
+	require.NoError(t, err)
+	compositor, err := Bind[*Compositor](registry, 3, 10)
+	require.NoError(t, err)
+	assert.Equal(t, compositor, c.objects[compositor.ObjectID])
+
+	buf := make([]byte, 12+8+32)
+	_, err = io.ReadFull(server, buf)
+	require.NoError(t, err)
+	body := buf[12+8:]
+	assert.Equal(t, uint32(3), binary.NativeEndian.Uint32(body[0:]))
+	assert.Equal(t, uint32(len("wl_compositor")+1), binary.NativeEndian.Uint32(body[4:]))
+	assert.Equal(t, "wl_compositor\x00\x00\x00", string(body[8:24]))
+	assert.Equal(t, uint32(CompositorInterface.Version), binary.NativeEndian.Uint32(body[24:]))
+	assert.Equal(t, uint32(compositor.ObjectID), binary.NativeEndian.Uint32(body[28:]))
+}
+
+type enterRecorder struct {
+	PointerListener
+	surfaces []*Surface
+}
+
+func (r *enterRecorder) Enter(serial uint32, surface *Surface, surfaceX Fixed, surfaceY Fixed) {
+	r.surfaces = append(r.surfaces, surface)
+}
+
+func TestUnsupportedVersion(t *testing.T) {
+	c, server := socketPair(t)
+	registry, err := c.Display().GetRegistry()
+	require.NoError(t, err)
+	compositor, err := Bind[*Compositor](registry, 3, 3)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(3), compositor.Version())
+	surface, err := compositor.CreateSurface()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(3), surface.Version(), "new objects inherit the version of their parent")
+
+	require.NoError(t, surface.SetBufferScale(2))
+	err = surface.DamageBuffer(0, 0, 10, 10)
+	assert.True(t, errors.Is(err, ErrUnsupportedVersion), "unexpected error %v", err)
+	assert.Contains(t, err.Error(), "wl_surface.damage_buffer: needs version 4, object has version 3")
+	assert.Equal(t, 4, SurfaceDamageBufferSinceVersion)
+
+	// get_registry, bind, create_surface and set_buffer_scale but no
+	// damage_buffer
+	buf := make([]byte, 12+(8+32)+12+12)
+	_, err = io.ReadFull(server, buf)
+	require.NoError(t, err)
+	require.NoError(t, server.SetReadDeadline(time.Now().Add(50*time.Millisecond)))
+	_, err = server.Read(make([]byte, 1))
+	assert.True(t, os.IsTimeout(err), "unexpected request sent: %v", err)
+}
+
+func TestObjectArguments(t *testing.T) {
+	c, server := socketPair(t)
+	compositor, pointer := &Compositor{}, &Pointer{}
+	require.NoError(t, c.NewProxy(compositor, 1))
+	require.NoError(t, c.NewProxy(pointer, 1))
+	surface, err := compositor.CreateSurface()
+	require.NoError(t, err)
+	rec := &enterRecorder{}
+	pointer.AddListener(rec)
+
+	for _, id := range []ObjectID{surface.ObjectID, 1000} {
+		msg := NewMessage(pointer.ObjectID, 0)
+		msg.PutUint(1)
+		msg.PutObject(id)
+		msg.PutFixed(0)
+		msg.PutFixed(0)
+		_, err = server.Write(msg.bytes())
+		require.NoError(t, err)
+	}
+	for len(rec.surfaces) < 2 {
+		require.NoError(t, c.Dispatch())
+	}
+	assert.Equal(t, []*Surface{surface, nil}, rec.surfaces)
+}
+
+type targetRecorder struct {
+	DataSourceListener
+	targets []*string
+}
+
+func (r *targetRecorder) Target(mimeType *string) {
+	r.targets = append(r.targets, mimeType)
+}
+
+func TestNullableArguments(t *testing.T) {
+	c, server := socketPair(t)
+	surface, shell, source := &Surface{}, &Shell{}, &DataSource{}
+	require.NoError(t, c.NewProxy(surface, 1))
+	require.NoError(t, c.NewProxy(shell, 1))
+	require.NoError(t, c.NewProxy(source, 1))
+
+	require.NoError(t, surface.Attach(nil, 0, 0))
+	buf := make([]byte, 20)
+	_, err := io.ReadFull(server, buf)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0), binary.NativeEndian.Uint32(buf[8:]))
+
+	_, err = shell.GetShellSurface(nil)
+	assert.True(t, errors.Is(err, ErrNullArgument))
+
+	rec := &targetRecorder{}
+	source.AddListener(rec)
+	for _, mimeType := range []*string{nil, new(string)} {
+		msg := NewMessage(source.ObjectID, 0)
+		msg.PutNullString(mimeType)
+		_, err = server.Write(msg.bytes())
+		require.NoError(t, err)
+	}
+	for len(rec.targets) < 2 {
+		require.NoError(t, c.Dispatch())
+	}
+	assert.Nil(t, rec.targets[0])
+	assert.Equal(t, "", *rec.targets[1])
+
+	// a null string is rejected where the protocol does not allow it
+	registry, err := c.Display().GetRegistry()
+	require.NoError(t, err)
+	msg := NewMessage(registry.ObjectID, 0)
+	msg.PutUint(1)
+	msg.PutNullString(nil)
+	msg.PutUint(1)
+	_, err = server.Write(msg.bytes())
+	require.NoError(t, err)
+	err = c.Dispatch()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "null string")
+	}
+}
+
+// serveRequests reads the requests the client writes to server, handing
+// each to handle until the connection is closed.
+func serveRequests(server *net.UnixConn, handle func(sender ObjectID, opcode uint16, body []byte)) {
+	buf := make([]byte, 0, 4*maxMessageSize)
+	chunk := make([]byte, maxMessageSize)
+	oob := make([]byte, syscall.CmsgSpace(maxFdsPerMessage*4))
+	for {
+		n, oobn, _, _, err := server.ReadMsgUnix(chunk, oob)
+		if err != nil {
+			return
+		}
+		if cmsgs, err := syscall.ParseSocketControlMessage(oob[:oobn]); err == nil {
+			for i := range cmsgs {
+				fds, _ := syscall.ParseUnixRights(&cmsgs[i])
+				for _, fd := range fds {
+					syscall.Close(fd)
+				}
+			}
+		}
+		buf = append(buf, chunk[:n]...)
+		for len(buf) >= headerSize {
+			word := binary.NativeEndian.Uint32(buf[4:])
+			size := int(word >> 16)
+			if len(buf) < size {
+				break
+			}
+			handle(ObjectID(binary.NativeEndian.Uint32(buf)), uint16(word), buf[headerSize:size])
+			buf = buf[size:]
+		}
+	}
+}
+
+// answerSync replies to a wl_display.sync with the callback's done event
+// followed by the deletion of its ID.
+func answerSync(server *net.UnixConn, body []byte, serial uint32) error {
+	cb := binary.NativeEndian.Uint32(body)
+	done := NewMessage(ObjectID(cb), 0)
+	done.PutUint(serial)
+	del := NewMessage(1, 1)
+	del.PutUint(cb)
+	_, err := server.Write(append(done.bytes(), del.bytes()...))
+	return err
+}
+
+func TestConcurrentRequests(t *testing.T) {
+	const workers, requests = 16, 50
+	c, server := socketPair(t)
+	compositor := &Compositor{}
+	require.NoError(t, c.NewProxy(compositor, 4))
+
+	// the server checks that every request arrives whole and, like
+	// libwayland, that a new ID is either free or the next unused one, and
+	// answers syncs
+	live := map[uint32]bool{1: true, uint32(compositor.ObjectID): true}
+	max := uint32(compositor.ObjectID)
+	var created, damaged, synced int
+	var serverErr error
+	fail := func(err error) {
+		if serverErr == nil {
+			serverErr = err
+		}
+	}
+	newID := func(body []byte) {
+		id := binary.NativeEndian.Uint32(body)
+		if live[id] || id > max+1 {
+			fail(fmt.Errorf("invalid new ID %d, highest is %d", id, max))
+		}
+		live[id] = true
+		if id > max {
+			max = id
+		}
+	}
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		serveRequests(server, func(sender ObjectID, opcode uint16, body []byte) {
+			switch {
+			case sender == compositor.ObjectID && opcode == 0:
+				newID(body)
+				created++
+			case sender == 1 && opcode == 0:
+				newID(body)
+				synced++
+				delete(live, binary.NativeEndian.Uint32(body))
+				if err := answerSync(server, body, uint32(synced)); err != nil {
+					fail(err)
+				}
+			case opcode == 2 && len(body) == 16 && live[uint32(sender)]:
+				damaged++
+			default:
+				fail(fmt.Errorf("unexpected request %d on %d with %d bytes", opcode, sender, len(body)))
+			}
+		})
+	}()
+
+	// dispatch the sync callbacks while the requests are sent
+	stop := make(chan struct{})
+	dispatched := make(chan error, 1)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				dispatched <- nil
+				return
+			default:
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			err := c.dispatchUntil(ctx, c.queue, func() bool { return false })
+			cancel()
+			if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+				dispatched <- err
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < requests; j++ {
+				surface, err := compositor.CreateSurface()
+				if !assert.NoError(t, err) {
+					return
+				}
+				assert.NoError(t, surface.Damage(0, 0, 10, 10))
+				_, err = c.Display().Sync()
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+	_, err := c.Roundtrip(context.Background())
+	require.NoError(t, err)
+	close(stop)
+	require.NoError(t, <-dispatched)
+
+	server.CloseWrite()
+	c.conn.Close()
+	<-served
+	require.NoError(t, serverErr)
+	assert.Equal(t, workers*requests, created)
+	assert.Equal(t, workers*requests, damaged)
+	assert.Equal(t, workers*requests+1, synced)
+}
+
+func TestConcurrentRoundtrip(t *testing.T) {
+	const workers, roundtrips = 8, 25
+	c, server := socketPair(t)
+	var serial uint32
+	go serveRequests(server, func(sender ObjectID, opcode uint16, body []byte) {
+		if sender == 1 && opcode == 0 {
+			serial++
+			answerSync(server, body, serial)
+		}
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < roundtrips; j++ {
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				_, err := c.Roundtrip(ctx)
+				cancel()
+				if !assert.NoError(t, err) {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	assert.Len(t, c.objects, 1, "every callback should have been deleted")
+}
@@ -0,0 +1,41 @@
+package wl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EnumEntry pairs the value of an enum entry with the name of its
+// generated constant.
+type EnumEntry struct {
+	Value uint32
+	Name  string
+}
+
+// EnumString formats a value that matches none of the entries of an enum.
+func EnumString(enum string, v uint32) string {
+	return fmt.Sprintf("%s(%d)", enum, v)
+}
+
+// BitfieldString formats v as the names of the flags it contains joined by
+// "|", followed by any bits that do not belong to a known flag.
+func BitfieldString(v uint32, entries []EnumEntry) string {
+	names := make([]string, 0)
+	rest := v
+	for _, e := range entries {
+		if e.Value == 0 {
+			if v == 0 {
+				return e.Name
+			}
+			continue
+		}
+		if rest&e.Value == e.Value {
+			names = append(names, e.Name)
+			rest &^= e.Value
+		}
+	}
+	if rest != 0 || len(names) == 0 {
+		names = append(names, fmt.Sprintf("%#x", rest))
+	}
+	return strings.Join(names, "|")
+}
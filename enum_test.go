@@ -0,0 +1,20 @@
+package wl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnumString(t *testing.T) {
+	assert.Equal(t, "OutputTransform90", OutputTransform90.String())
+	assert.Equal(t, "OutputTransform(42)", OutputTransform(42).String())
+
+	caps := SeatCapabilityPointer.Set(SeatCapabilityTouch)
+	assert.True(t, caps.Has(SeatCapabilityPointer))
+	assert.False(t, caps.Has(SeatCapabilityKeyboard))
+	assert.Equal(t, "SeatCapabilityPointer|SeatCapabilityTouch", caps.String())
+	assert.Equal(t, "SeatCapabilityTouch|0x10", caps.Clear(SeatCapabilityPointer).Set(0x10).String())
+	assert.Equal(t, "0x0", SeatCapability(0).String())
+	assert.Equal(t, "DataDeviceManagerDndActionNone", DataDeviceManagerDndAction(0).String())
+}
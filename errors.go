@@ -0,0 +1,55 @@
+package wl
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+)
+
+// ErrNullArgument is returned by a request when nil is passed for an
+// argument that the protocol does not allow to be null.
+var ErrNullArgument = errors.New("argument must not be null")
+
+// NullArgument returns ErrNullArgument annotated with the request and the
+// name of the offending argument.
+func NullArgument(request string, arg string) error {
+	return errors.Wrapf(ErrNullArgument, "%s: %s", request, arg)
+}
+
+// ErrNotConnected is returned by a Client that has not been connected yet,
+// and by requests on objects that were never registered with one.
+var ErrNotConnected = errors.New("client is not connected")
+
+// ErrObjectDestroyed is returned by requests on an object after one of its
+// destructor requests has been sent.
+var ErrObjectDestroyed = errors.New("object has been destroyed")
+
+// ErrUnsupportedVersion is returned by a request that is newer than the
+// version the object was bound with. Sending it would be a protocol error.
+var ErrUnsupportedVersion = errors.New("request is not supported by the object version")
+
+// UnsupportedVersion returns ErrUnsupportedVersion annotated with the
+// request, the version it was introduced in and the version of the object.
+func UnsupportedVersion(request string, since uint32, version uint32) error {
+	return errors.Wrapf(ErrUnsupportedVersion, "%s: needs version %d, object has version %d", request, since, version)
+}
+
+// ProtocolError is a fatal error reported by the server with the
+// wl_display.error event. Once it has been received the connection is
+// dead and every further request or dispatch returns the same error.
+type ProtocolError struct {
+	ObjectID  ObjectID
+	Interface string
+	Code      uint32
+	// Name is the generated constant for Code, such as
+	// ShmErrorInvalidStride, or empty if the interface does not declare it.
+	Name    string
+	Message string
+}
+
+func (e *ProtocolError) Error() string {
+	code := fmt.Sprint(e.Code)
+	if e.Name != "" {
+		code = fmt.Sprintf("%d (%s)", e.Code, e.Name)
+	}
+	return fmt.Sprintf("protocol error on %s@%d: error %s: %s", e.Interface, e.ObjectID, code, e.Message)
+}
@@ -0,0 +1,165 @@
+package wl
+
+import (
+	"context"
+	"sync"
+)
+
+// EventBuffer is the number of events a subscription holds for a receiver
+// that has not caught up yet.
+const EventBuffer = 64
+
+// subscriber is implemented by the subscriptions to the events of an
+// object.
+type subscriber interface {
+	publish(ev interface{})
+	close()
+}
+
+// subscription delivers the events of type E published by an object on a
+// buffered channel.
+type subscription[E any] struct {
+	mutex  sync.Mutex
+	events chan E
+	closed bool
+	// stop unregisters the function closing the subscription when its
+	// context is done.
+	stop func() bool
+}
+
+// publish queues ev without blocking, dropping the oldest buffered event if
+// the buffer is full.
+func (s *subscription[E]) publish(ev interface{}) {
+	e, ok := ev.(E)
+	if !ok {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return
+	}
+	for {
+		select {
+		case s.events <- e:
+			return
+		default:
+		}
+		select {
+		case <-s.events:
+		default:
+		}
+	}
+}
+
+func (s *subscription[E]) close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.events)
+		if s.stop != nil {
+			s.stop()
+		}
+	}
+}
+
+// setStop records the stop function of the context the subscription ends
+// with, calling it right away if the subscription has already been closed.
+func (s *subscription[E]) setStop(stop func() bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		stop()
+		return
+	}
+	s.stop = stop
+}
+
+// subscribe adds a subscription to the object of p, which is closed right
+// away if the object has been destroyed. It is called with the mutex held.
+func subscribe[E any](p *Proxy) *subscription[E] {
+	s := &subscription[E]{events: make(chan E, EventBuffer)}
+	if p.destroyed {
+		s.close()
+		return s
+	}
+	p.subscribers = append(p.subscribers, s)
+	return s
+}
+
+// Subscribe returns a channel receiving the events of the object of p as
+// they are dispatched from its queue, after its listeners have been called.
+// Generated types wrap it in their Subscribe method, with E the event type
+// of the interface. The channel is closed once ctx is done or the object is
+// destroyed, by a destructor request or, after the events the object
+// received until then, by the server deleting its ID.
+//
+// Dispatching never waits for a subscriber: each subscription buffers up
+// to EventBuffer events and when a receiver falls further behind the
+// oldest buffered event is dropped to make room, so the receiver always
+// sees the latest events. A file descriptor carried by an event is shared
+// with the listeners and every other subscription of the object.
+func Subscribe[E any](p *Proxy, ctx context.Context) <-chan E {
+	if p.wrapped != nil {
+		p = p.wrapped
+	}
+	unlock := p.lock()
+	s := subscribe[E](p)
+	unlock()
+	s.setStop(context.AfterFunc(ctx, func() {
+		p.unsubscribe(s)
+		s.close()
+	}))
+	return s.events
+}
+
+// Events returns the subscription of the object of p that lasts until the
+// object is destroyed, creating it on the first call and returning the
+// same channel afterwards. Generated types wrap it in their Events method.
+// The subscription is buffered like the ones of Subscribe.
+func Events[E any](p *Proxy) <-chan E {
+	if p.wrapped != nil {
+		p = p.wrapped
+	}
+	defer p.lock()()
+	if s, ok := p.events.(*subscription[E]); ok {
+		return s.events
+	}
+	s := subscribe[E](p)
+	p.events = s
+	return s.events
+}
+
+// Publish hands ev to the subscriptions of the object. Generated types
+// call it as each of their events is dispatched.
+func (p *Proxy) Publish(ev interface{}) {
+	unlock := p.lock()
+	subscribers := p.subscribers
+	unlock()
+	for _, s := range subscribers {
+		s.publish(ev)
+	}
+}
+
+// unsubscribe removes s from the subscriptions of the object. The slice is
+// copied, as Publish may still be iterating over the old one.
+func (p *Proxy) unsubscribe(s subscriber) {
+	defer p.lock()()
+	subscribers := make([]subscriber, 0, len(p.subscribers))
+	for _, other := range p.subscribers {
+		if other != s {
+			subscribers = append(subscribers, other)
+		}
+	}
+	p.subscribers = subscribers
+}
+
+// closeSubscriptions closes and removes every subscription of the object.
+// It is called with the mutex held.
+func (p *Proxy) closeSubscriptions() {
+	for _, s := range p.subscribers {
+		s.close()
+	}
+	p.subscribers = nil
+}
@@ -0,0 +1,126 @@
+package wl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscribe(t *testing.T) {
+	c, server := socketPair(t)
+	registry, err := c.Display().GetRegistry()
+	require.NoError(t, err)
+	rec := &registryRecorder{globals: make(map[uint32]string)}
+	registry.AddListener(rec)
+	events := registry.Events()
+
+	sendGlobal(t, server, registry, 1, "wl_seat", 5)
+	require.NoError(t, c.Dispatch())
+	assert.Equal(t, RegistryGlobalEvent{Name: 1, Interface: "wl_seat", Version: 5}, <-events)
+	// the listener still runs alongside the subscription
+	assert.Equal(t, 1, rec.events)
+
+	msg := NewMessage(registry.ObjectID, 1)
+	msg.PutUint(1)
+	_, err = server.Write(msg.bytes())
+	require.NoError(t, err)
+	require.NoError(t, c.Dispatch())
+	ev := <-events
+	switch ev := ev.(type) {
+	case RegistryGlobalRemoveEvent:
+		assert.Equal(t, uint32(1), ev.Name)
+	default:
+		t.Fatalf("unexpected event %#v", ev)
+	}
+}
+
+func TestSubscribeOverflow(t *testing.T) {
+	c, server := socketPair(t)
+	registry, err := c.Display().GetRegistry()
+	require.NoError(t, err)
+	rec := &registryRecorder{globals: make(map[uint32]string)}
+	registry.AddListener(rec)
+	events := registry.Events()
+
+	for name := uint32(0); name < EventBuffer+10; name++ {
+		sendGlobal(t, server, registry, name, "wl_output", 4)
+	}
+	for rec.events < EventBuffer+10 {
+		require.NoError(t, c.Dispatch())
+	}
+
+	// the oldest events make room for the latest ones
+	require.Len(t, events, EventBuffer)
+	first := (<-events).(RegistryGlobalEvent)
+	assert.Equal(t, uint32(10), first.Name)
+	var last RegistryEvent
+	for len(events) > 0 {
+		last = <-events
+	}
+	assert.Equal(t, uint32(EventBuffer+9), last.(RegistryGlobalEvent).Name)
+}
+
+func TestSubscribeClose(t *testing.T) {
+	c, server := socketPair(t)
+	registry, err := c.Display().GetRegistry()
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := registry.Subscribe(ctx)
+	kept := registry.Events()
+	cancel()
+	_, ok := <-cancelled
+	assert.False(t, ok)
+
+	sendGlobal(t, server, registry, 1, "wl_seat", 5)
+	require.NoError(t, c.Dispatch())
+	assert.Len(t, kept, 1)
+	// Events returns the same subscription every time
+	assert.Equal(t, kept, registry.Events())
+	assert.Len(t, registry.subscribers, 1)
+
+	// destroying the object closes its subscriptions
+	compositor, err := Bind[*Compositor](registry, 2, 4)
+	require.NoError(t, err)
+	surface, err := compositor.CreateSurface()
+	require.NoError(t, err)
+	events := surface.Events()
+	subscribed := surface.Subscribe(context.Background())
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	surface.Subscribe(ctx)
+	stop := surface.subscribers[2].(*subscription[SurfaceEvent]).stop
+	require.NoError(t, surface.Destroy())
+	_, ok = <-events
+	assert.False(t, ok)
+	_, ok = <-subscribed
+	assert.False(t, ok)
+	// the subscription no longer waits for its context
+	assert.False(t, stop())
+	_, ok = <-surface.Events()
+	assert.False(t, ok)
+}
+
+func TestSubscribeServerDestroyed(t *testing.T) {
+	c, server := socketPair(t)
+	callback, err := c.Display().Sync()
+	require.NoError(t, err)
+	events := callback.Events()
+
+	done := NewMessage(callback.ObjectID, 0)
+	done.PutUint(7)
+	del := NewMessage(c.Display().ObjectID, 1)
+	del.PutUint(uint32(callback.ObjectID))
+	_, err = server.Write(append(done.bytes(), del.bytes()...))
+	require.NoError(t, err)
+	require.NoError(t, c.Dispatch())
+
+	// the done event is delivered before the channel is closed
+	var received []CallbackEvent
+	for ev := range events {
+		received = append(received, ev)
+	}
+	assert.Equal(t, []CallbackEvent{CallbackDoneEvent{CallbackData: 7}}, received)
+	assert.Equal(t, ErrObjectDestroyed, callback.Err())
+}
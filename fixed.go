@@ -0,0 +1,49 @@
+package wl
+
+import (
+	"math"
+	"strconv"
+)
+
+// Fixed is a signed 24.8 fixed point number, the wire representation of
+// wl_fixed_t used for surface coordinates and axis values.
+type Fixed int32
+
+// FixedFromFloat converts f to the nearest representable Fixed.
+func FixedFromFloat(f float64) Fixed {
+	return Fixed(math.Round(f * 256))
+}
+
+// FixedFromInt converts i to a Fixed.
+func FixedFromInt(i int) Fixed {
+	return Fixed(i << 8)
+}
+
+func (f Fixed) Float64() float64 {
+	return float64(f) / 256
+}
+
+// Int returns the integer part of f, truncated towards zero.
+func (f Fixed) Int() int {
+	return int(f / 256)
+}
+
+func (f Fixed) Add(g Fixed) Fixed {
+	return f + g
+}
+
+func (f Fixed) Sub(g Fixed) Fixed {
+	return f - g
+}
+
+func (f Fixed) Mul(g Fixed) Fixed {
+	return Fixed(int64(f) * int64(g) >> 8)
+}
+
+func (f Fixed) Div(g Fixed) Fixed {
+	return Fixed(int64(f) << 8 / int64(g))
+}
+
+func (f Fixed) String() string {
+	return strconv.FormatFloat(f.Float64(), 'f', -1, 64)
+}
@@ -0,0 +1,20 @@
+package wl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixed(t *testing.T) {
+	assert.Equal(t, Fixed(0x180), FixedFromFloat(1.5))
+	assert.Equal(t, Fixed(-0x180), FixedFromFloat(-1.5))
+	assert.Equal(t, -1.5, Fixed(-0x180).Float64())
+	assert.Equal(t, Fixed(0x300), FixedFromInt(3))
+	assert.Equal(t, -1, FixedFromFloat(-1.75).Int())
+	assert.Equal(t, FixedFromFloat(3.75), FixedFromFloat(2.5).Mul(FixedFromFloat(1.5)))
+	assert.Equal(t, FixedFromFloat(1.25), FixedFromFloat(2.5).Div(FixedFromInt(2)))
+	assert.Equal(t, FixedFromFloat(4), FixedFromFloat(2.5).Add(FixedFromFloat(1.5)))
+	assert.Equal(t, FixedFromFloat(1), FixedFromFloat(2.5).Sub(FixedFromFloat(1.5)))
+	assert.Equal(t, "-0.25", FixedFromFloat(-0.25).String())
+}
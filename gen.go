@@ -0,0 +1,3 @@
+package wl
+
+//go:generate go run ./wlgen -i wlgen/wayland.xml -o protocol.go -pkg wl
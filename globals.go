@@ -0,0 +1,165 @@
+package wl
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"time"
+)
+
+// ErrGlobalRemoved is returned by requests on an object bound through
+// Globals after the server has removed its global. Destructor requests
+// remain available so the object can still be released.
+var ErrGlobalRemoved = errors.New("global has been removed")
+
+// Global is a global object advertised by the server through wl_registry.
+type Global struct {
+	Name      uint32
+	Interface string
+	Version   uint32
+}
+
+type globalSubscription struct {
+	iface   string
+	added   func(Global)
+	removed func(Global)
+}
+
+// Globals keeps a live view of the globals advertised on a registry. It
+// adds itself as a listener of the registry, alongside any other listeners
+// it has. Globals is updated while the registry's events are dispatched and
+// is not safe for concurrent use, it should only be used from the goroutine
+// dispatching them.
+type Globals struct {
+	registry *Registry
+	globals  map[uint32]Global
+	bound    map[uint32][]proxied
+	subs     []*globalSubscription
+}
+
+// NewGlobals starts tracking the globals of registry. The initial burst of
+// globals arrives as events, use Client.Roundtrip or WaitFor to receive it.
+func NewGlobals(registry *Registry) *Globals {
+	g := &Globals{
+		registry: registry,
+		globals:  make(map[uint32]Global),
+		bound:    make(map[uint32][]proxied),
+	}
+	registry.AddListener(g)
+	return g
+}
+
+// Global implements RegistryListener.
+func (g *Globals) Global(name uint32, iface string, version uint32) {
+	global := Global{Name: name, Interface: iface, Version: version}
+	g.globals[name] = global
+	for _, sub := range g.subs {
+		if sub.iface == iface && sub.added != nil {
+			sub.added(global)
+		}
+	}
+}
+
+// GlobalRemove implements RegistryListener. Objects bound to the global are
+// invalidated before the subscribers are notified.
+func (g *Globals) GlobalRemove(name uint32) {
+	global, ok := g.globals[name]
+	if !ok {
+		return
+	}
+	delete(g.globals, name)
+	for _, obj := range g.bound[name] {
+		obj.proxy().invalidate(ErrGlobalRemoved)
+	}
+	delete(g.bound, name)
+	for _, sub := range g.subs {
+		if sub.iface == global.Interface && sub.removed != nil {
+			sub.removed(global)
+		}
+	}
+}
+
+// Lookup returns the global with the given name.
+func (g *Globals) Lookup(name uint32) (Global, bool) {
+	global, ok := g.globals[name]
+	return global, ok
+}
+
+// Find returns every known global implementing iface.
+func (g *Globals) Find(iface string) []Global {
+	found := make([]Global, 0)
+	for _, global := range g.globals {
+		if global.Interface == iface {
+			found = append(found, global)
+		}
+	}
+	return found
+}
+
+// WaitFor dispatches events until a global implementing iface is known and
+// returns it, giving up after timeout.
+func (g *Globals) WaitFor(iface string, timeout time.Duration) (Global, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	var found []Global
+	err := g.registry.client.dispatchUntil(ctx, g.registry.eventQueue(), func() bool {
+		found = g.Find(iface)
+		return len(found) > 0
+	})
+	if err != nil {
+		return Global{}, errors.Wrapf(err, "global %s not available", iface)
+	}
+	return found[0], nil
+}
+
+// Subscribe registers callbacks for the globals implementing iface. added
+// is called right away for each matching global already known and then for
+// every one announced later, removed whenever one is withdrawn. Either may
+// be nil. The returned function cancels the subscription.
+func (g *Globals) Subscribe(iface string, added, removed func(Global)) func() {
+	sub := &globalSubscription{iface: iface, added: added, removed: removed}
+	// subs is replaced rather than modified, as Global and GlobalRemove may
+	// be ranging over it when a callback subscribes or cancels
+	g.subs = append(g.subs[:len(g.subs):len(g.subs)], sub)
+	if added != nil {
+		for _, global := range g.Find(iface) {
+			added(global)
+		}
+	}
+	return func() {
+		for i, s := range g.subs {
+			if s == sub {
+				g.subs = append(g.subs[:i:i], g.subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Bind binds the global with the given name using the version it was
+// advertised with, capped to the version of iface this package implements.
+// The object is invalidated if the global is later removed.
+func (g *Globals) Bind(name uint32, iface *Interface) (Object, error) {
+	global, ok := g.globals[name]
+	if !ok {
+		return nil, errors.Errorf("unknown global %d", name)
+	}
+	if global.Interface != iface.Name {
+		return nil, errors.Errorf("global %d is a %s, not a %s", name, global.Interface, iface.Name)
+	}
+	obj, err := g.registry.Bind(name, iface, global.Version)
+	if err != nil {
+		return nil, err
+	}
+	g.bound[name] = append(g.bound[name], obj.(proxied))
+	return obj, nil
+}
+
+// BindGlobal is the typed form of Globals.Bind.
+func BindGlobal[T proxied](g *Globals, name uint32) (T, error) {
+	var zero T
+	obj, err := g.Bind(name, zero.Interface())
+	if err != nil {
+		return zero, err
+	}
+	return obj.(T), nil
+}
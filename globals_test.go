@@ -0,0 +1,83 @@
+package wl
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sendGlobal(t *testing.T, server net.Conn, registry *Registry, name uint32, iface string, version uint32) {
+	msg := NewMessage(registry.ObjectID, 0)
+	msg.PutUint(name)
+	msg.PutString(iface)
+	msg.PutUint(version)
+	_, err := server.Write(msg.bytes())
+	require.NoError(t, err)
+}
+
+func TestGlobals(t *testing.T) {
+	c, server := socketPair(t)
+	registry, err := c.Display().GetRegistry()
+	require.NoError(t, err)
+	globals := NewGlobals(registry)
+
+	var added, removed []Global
+	cancel := globals.Subscribe("wl_seat",
+		func(g Global) { added = append(added, g) },
+		func(g Global) { removed = append(removed, g) })
+	defer cancel()
+
+	sendGlobal(t, server, registry, 1, "wl_compositor", 4)
+	sendGlobal(t, server, registry, 2, "wl_seat", 7)
+	global, err := globals.WaitFor("wl_seat", time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, Global{Name: 2, Interface: "wl_seat", Version: 7}, global)
+	assert.Equal(t, []Global{global}, added)
+
+	_, err = globals.WaitFor("wl_output", 10*time.Millisecond)
+	assert.Error(t, err)
+
+	seat, err := BindGlobal[*Seat](globals, 2)
+	require.NoError(t, err)
+	_, err = BindGlobal[*Seat](globals, 1)
+	assert.Error(t, err)
+
+	msg := NewMessage(registry.ObjectID, 1)
+	msg.PutUint(2)
+	_, err = server.Write(msg.bytes())
+	require.NoError(t, err)
+	require.NoError(t, c.Dispatch())
+	assert.Equal(t, []Global{global}, removed)
+	assert.Empty(t, globals.Find("wl_seat"))
+
+	_, err = seat.GetPointer()
+	assert.Equal(t, ErrGlobalRemoved, err)
+	assert.NoError(t, seat.Release())
+}
+
+func TestGlobalsCancelFromCallback(t *testing.T) {
+	c, server := socketPair(t)
+	registry, err := c.Display().GetRegistry()
+	require.NoError(t, err)
+	globals := NewGlobals(registry)
+
+	var calls []string
+	var cancelA func()
+	cancelA = globals.Subscribe("wl_output", func(Global) {
+		calls = append(calls, "A")
+		cancelA()
+	}, nil)
+	globals.Subscribe("wl_output", func(Global) { calls = append(calls, "B") }, nil)
+	globals.Subscribe("wl_output", func(Global) { calls = append(calls, "C") }, nil)
+
+	sendGlobal(t, server, registry, 1, "wl_output", 4)
+	require.NoError(t, c.Dispatch())
+	assert.Equal(t, []string{"A", "B", "C"}, calls)
+
+	sendGlobal(t, server, registry, 2, "wl_output", 4)
+	require.NoError(t, c.Dispatch())
+	assert.Equal(t, []string{"A", "B", "C", "B", "C"}, calls)
+}
@@ -0,0 +1,45 @@
+package wl
+
+import (
+	"github.com/pkg/errors"
+)
+
+const (
+	// clientIDStart is the first ID handed out for client created objects.
+	// ID 0 is reserved for the null object, so wl_display is always 1.
+	clientIDStart ObjectID = 1
+	// serverIDStart is the first ID of the range the server allocates from
+	// when it creates objects, such as wl_data_device.data_offer.
+	serverIDStart ObjectID = 0xff000000
+)
+
+// idAllocator hands out client object IDs for a single connection. IDs are
+// only reused once the server has acknowledged the destruction of their
+// object with wl_display.delete_id.
+type idAllocator struct {
+	next ObjectID
+	free []ObjectID
+}
+
+func (a *idAllocator) alloc() (ObjectID, error) {
+	if n := len(a.free); n > 0 {
+		id := a.free[n-1]
+		a.free = a.free[:n-1]
+		return id, nil
+	}
+	if a.next < clientIDStart {
+		a.next = clientIDStart
+	}
+	if a.next >= serverIDStart {
+		return 0, errors.New("client object ID space exhausted")
+	}
+	id := a.next
+	a.next++
+	return id, nil
+}
+
+func (a *idAllocator) release(id ObjectID) {
+	if id >= clientIDStart && id < a.next {
+		a.free = append(a.free, id)
+	}
+}
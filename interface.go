@@ -0,0 +1,25 @@
+package wl
+
+// Interface describes a protocol interface as declared in its XML
+// definition. Every generated type has a matching XxxInterface variable.
+type Interface struct {
+	Name    string
+	Version uint32
+	// Errors maps the values of the interface's error enum to the names of
+	// their generated constants.
+	Errors map[uint32]string
+	// New returns an unregistered proxy of the generated type.
+	New func() Object
+}
+
+// Bind binds the global with the given name to a new object of type T,
+// such as *Compositor or *Seat. The version should be the one advertised
+// by the global and is capped to the version this package implements.
+func Bind[T proxied](r *Registry, name uint32, version uint32) (T, error) {
+	var zero T
+	obj, err := r.Bind(name, zero.Interface(), version)
+	if err != nil {
+		return zero, err
+	}
+	return obj.(T), nil
+}
@@ -1,24 +1,159 @@
 package wl
 
-
-const DisplayErrorInvalidObject = 0 // server couldn't find object
-const DisplayErrorInvalidMethod = 1 // method doesn't exist on the specified interface
-const DisplayErrorNoMemory = 2 // server is out of memory
+import (
+	"context"
+	"os"
+)
+
+// These errors are global and can be emitted in response to any
+// server request.
+type DisplayError uint32
+
+const DisplayErrorInvalidObject DisplayError = 0 // server couldn't find object
+const DisplayErrorInvalidMethod DisplayError = 1 // method doesn't exist on the specified interface
+const DisplayErrorNoMemory DisplayError = 2      // server is out of memory
+
+func (e DisplayError) String() string {
+	switch e {
+	case DisplayErrorInvalidObject:
+		return "DisplayErrorInvalidObject"
+	case DisplayErrorInvalidMethod:
+		return "DisplayErrorInvalidMethod"
+	case DisplayErrorNoMemory:
+		return "DisplayErrorNoMemory"
+	}
+	return EnumString("DisplayError", uint32(e))
+}
+
+const (
+	DisplaySyncSinceVersion        = 1
+	DisplayGetRegistrySinceVersion = 1
+	DisplayErrorSinceVersion       = 1
+	DisplayDeleteIDSinceVersion    = 1
+)
 
 type DisplayListener interface {
-    Error(objectID uint32, code uint32, message string)
-    DeleteID(id uint32)
+	Error(objectID Object, code uint32, message string)
+	DeleteID(id uint32)
+}
+
+// DisplayListenerFuncs implements DisplayListener with a function per
+// event. Events whose function is nil are ignored.
+type DisplayListenerFuncs struct {
+	OnError    func(objectID Object, code uint32, message string)
+	OnDeleteID func(id uint32)
+}
+
+func (this DisplayListenerFuncs) Error(objectID Object, code uint32, message string) {
+	if this.OnError != nil {
+		this.OnError(objectID, code, message)
+	}
+}
+
+func (this DisplayListenerFuncs) DeleteID(id uint32) {
+	if this.OnDeleteID != nil {
+		this.OnDeleteID(id)
+	}
+}
+
+// DisplayEvent is an event of a wl_display, one of the Display*Event types.
+type DisplayEvent interface {
+	isDisplayEvent()
+}
+
+// DisplayErrorEvent is the wl_display.error event.
+type DisplayErrorEvent struct {
+	ObjectID Object
+	Code     uint32
+	Message  string
+}
+
+func (DisplayErrorEvent) isDisplayEvent() {}
+
+// DisplayDeleteIDEvent is the wl_display.delete_id event.
+type DisplayDeleteIDEvent struct {
+	ID uint32
+}
+
+func (DisplayDeleteIDEvent) isDisplayEvent() {}
+
+var DisplayInterface = &Interface{
+	Name:    "wl_display",
+	Version: 1,
+	New:     func() Object { return &Display{} },
+	Errors: map[uint32]string{
+		uint32(DisplayErrorInvalidObject): "DisplayErrorInvalidObject",
+		uint32(DisplayErrorInvalidMethod): "DisplayErrorInvalidMethod",
+		uint32(DisplayErrorNoMemory):      "DisplayErrorNoMemory",
+	},
 }
 
 // The core global object.  This is a special singleton object.  It
 // is used for internal Wayland protocol features.
 type Display struct {
-    ObjectID
-    listener DisplayListener
-}
-
-func (this *Display) AddListener(listener DisplayListener) {
-    this.listener = listener
+	Proxy
+}
+
+// AddListener adds a listener for the events of the wl_display and returns
+// the function that removes it again.
+func (this *Display) AddListener(listener DisplayListener) func() {
+	return this.Proxy.AddListener(listener)
+}
+
+// Subscribe returns a channel receiving the events of the wl_display as
+// they are dispatched, until ctx is done or the object is destroyed. See
+// the Subscribe function for how events are buffered.
+func (this *Display) Subscribe(ctx context.Context) <-chan DisplayEvent {
+	return Subscribe[DisplayEvent](&this.Proxy, ctx)
+}
+
+// Events returns the channel receiving the events of the wl_display until it
+// is destroyed. Every call returns the same channel, use Subscribe for
+// another receiver.
+func (this *Display) Events() <-chan DisplayEvent {
+	return Events[DisplayEvent](&this.Proxy)
+}
+
+func (this *Display) Interface() *Interface {
+	return DisplayInterface
+}
+
+func (this *Display) Decode(ev *Event) (func(), error) {
+	switch ev.Opcode {
+	case 0:
+		objectID := ev.GetProxy(this.Proxy.Client())
+		code := ev.GetUint()
+		message := ev.GetString()
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(DisplayListener); ok {
+					listener.Error(objectID, code, message)
+				}
+			}
+			this.Proxy.Publish(DisplayErrorEvent{ObjectID: objectID, Code: code, Message: message})
+		}, nil
+	case 1:
+		id := ev.GetUint()
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(DisplayListener); ok {
+					listener.DeleteID(id)
+				}
+			}
+			this.Proxy.Publish(DisplayDeleteIDEvent{ID: id})
+		}, nil
+	}
+	return nil, ev.InvalidOpcode()
+}
+
+func (this *Display) EventFds(opcode uint16) int {
+	return 0
 }
 
 // The sync request asks the server to emit the 'done' event
@@ -26,28 +161,90 @@ func (this *Display) AddListener(listener DisplayListener) {
 // handled in-order and events are delivered in-order, this can
 // be used as a barrier to ensure all previous requests and the
 // resulting events have been handled.
-// 
+//
 // The object returned by this request will be destroyed by the
 // compositor after the callback is fired and as such the client must not
 // attempt to use it after that point.
-// 
+//
 // The callback_data passed in the callback is the event serial.
 func (this *Display) Sync() (*Callback, error) {
-    return nil, nil
+	ret := &Callback{}
+	msg := NewMessage(this.ObjectID, 0)
+	msg.PutNewID(ret, this.Proxy.Version())
+	if err := this.Proxy.Send(msg); err != nil {
+		return nil, err
+	}
+	return ret, nil
 }
 
 // This request creates a registry object that allows the client
 // to list and bind the global objects available from the
 // compositor.
 func (this *Display) GetRegistry() (*Registry, error) {
-    return nil, nil
+	ret := &Registry{}
+	msg := NewMessage(this.ObjectID, 1)
+	msg.PutNewID(ret, this.Proxy.Version())
+	if err := this.Proxy.Send(msg); err != nil {
+		return nil, err
+	}
+	return ret, nil
 }
 
-
+const (
+	RegistryBindSinceVersion         = 1
+	RegistryGlobalSinceVersion       = 1
+	RegistryGlobalRemoveSinceVersion = 1
+)
 
 type RegistryListener interface {
-    Global(name uint32, iface string, version uint32)
-    GlobalRemove(name uint32)
+	Global(name uint32, iface string, version uint32)
+	GlobalRemove(name uint32)
+}
+
+// RegistryListenerFuncs implements RegistryListener with a function per
+// event. Events whose function is nil are ignored.
+type RegistryListenerFuncs struct {
+	OnGlobal       func(name uint32, iface string, version uint32)
+	OnGlobalRemove func(name uint32)
+}
+
+func (this RegistryListenerFuncs) Global(name uint32, iface string, version uint32) {
+	if this.OnGlobal != nil {
+		this.OnGlobal(name, iface, version)
+	}
+}
+
+func (this RegistryListenerFuncs) GlobalRemove(name uint32) {
+	if this.OnGlobalRemove != nil {
+		this.OnGlobalRemove(name)
+	}
+}
+
+// RegistryEvent is an event of a wl_registry, one of the Registry*Event types.
+type RegistryEvent interface {
+	isRegistryEvent()
+}
+
+// RegistryGlobalEvent is the wl_registry.global event.
+type RegistryGlobalEvent struct {
+	Name      uint32
+	Interface string
+	Version   uint32
+}
+
+func (RegistryGlobalEvent) isRegistryEvent() {}
+
+// RegistryGlobalRemoveEvent is the wl_registry.global_remove event.
+type RegistryGlobalRemoveEvent struct {
+	Name uint32
+}
+
+func (RegistryGlobalRemoveEvent) isRegistryEvent() {}
+
+var RegistryInterface = &Interface{
+	Name:    "wl_registry",
+	Version: 1,
+	New:     func() Object { return &Registry{} },
 }
 
 // The singleton global registry object.  The server has a number of
@@ -55,7 +252,7 @@ type RegistryListener interface {
 // typically represent an actual object in the server (for example,
 // an input device) or they are singleton objects that provide
 // extension functionality.
-// 
+//
 // When a client creates a registry object, the registry object
 // will emit a global event for each global currently in the
 // registry.  Globals come and go as a result of device or
@@ -65,75 +262,265 @@ type RegistryListener interface {
 // of the initial burst of events, the client can use the
 // wl_display.sync request immediately after calling
 // wl_display.get_registry.
-// 
+//
 // A client can bind to a global object by using the bind
 // request.  This creates a client-side handle that lets the object
 // emit events to the client and lets the client invoke requests on
 // the object.
 type Registry struct {
-    ObjectID
-    listener RegistryListener
-}
-
-func (this *Registry) AddListener(listener RegistryListener) {
-    this.listener = listener
+	Proxy
+}
+
+// AddListener adds a listener for the events of the wl_registry and returns
+// the function that removes it again.
+func (this *Registry) AddListener(listener RegistryListener) func() {
+	return this.Proxy.AddListener(listener)
+}
+
+// Subscribe returns a channel receiving the events of the wl_registry as
+// they are dispatched, until ctx is done or the object is destroyed. See
+// the Subscribe function for how events are buffered.
+func (this *Registry) Subscribe(ctx context.Context) <-chan RegistryEvent {
+	return Subscribe[RegistryEvent](&this.Proxy, ctx)
+}
+
+// Events returns the channel receiving the events of the wl_registry until it
+// is destroyed. Every call returns the same channel, use Subscribe for
+// another receiver.
+func (this *Registry) Events() <-chan RegistryEvent {
+	return Events[RegistryEvent](&this.Proxy)
+}
+
+func (this *Registry) Interface() *Interface {
+	return RegistryInterface
+}
+
+func (this *Registry) Decode(ev *Event) (func(), error) {
+	switch ev.Opcode {
+	case 0:
+		name := ev.GetUint()
+		iface := ev.GetString()
+		version := ev.GetUint()
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(RegistryListener); ok {
+					listener.Global(name, iface, version)
+				}
+			}
+			this.Proxy.Publish(RegistryGlobalEvent{Name: name, Interface: iface, Version: version})
+		}, nil
+	case 1:
+		name := ev.GetUint()
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(RegistryListener); ok {
+					listener.GlobalRemove(name)
+				}
+			}
+			this.Proxy.Publish(RegistryGlobalRemoveEvent{Name: name})
+		}, nil
+	}
+	return nil, ev.InvalidOpcode()
+}
+
+func (this *Registry) EventFds(opcode uint16) int {
+	return 0
 }
 
 // Binds a new, client-created object to the server using the
 // specified name as the identifier.
-func (this *Registry) Bind(name uint32) error {
-    return nil
+func (this *Registry) Bind(name uint32, iface *Interface, version uint32) (Object, error) {
+	if iface == nil {
+		return nil, NullArgument("wl_registry.bind", "iface")
+	}
+	if version > iface.Version {
+		version = iface.Version
+	}
+	ret := iface.New()
+	msg := NewMessage(this.ObjectID, 0)
+	msg.PutUint(name)
+	msg.PutString(iface.Name)
+	msg.PutUint(version)
+	msg.PutNewID(ret, version)
+	if err := this.Proxy.Send(msg); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+const (
+	CallbackDoneSinceVersion = 1
+)
+
+type CallbackListener interface {
+	Done(callbackData uint32)
 }
 
+// CallbackListenerFuncs implements CallbackListener with a function per
+// event. Events whose function is nil are ignored.
+type CallbackListenerFuncs struct {
+	OnDone func(callbackData uint32)
+}
 
+func (this CallbackListenerFuncs) Done(callbackData uint32) {
+	if this.OnDone != nil {
+		this.OnDone(callbackData)
+	}
+}
 
-type CallbackListener interface {
-    Done(callbackData uint32)
+// CallbackEvent is an event of a wl_callback, one of the Callback*Event types.
+type CallbackEvent interface {
+	isCallbackEvent()
+}
+
+// CallbackDoneEvent is the wl_callback.done event.
+type CallbackDoneEvent struct {
+	CallbackData uint32
+}
+
+func (CallbackDoneEvent) isCallbackEvent() {}
+
+var CallbackInterface = &Interface{
+	Name:    "wl_callback",
+	Version: 1,
+	New:     func() Object { return &Callback{} },
 }
 
 // Clients can handle the 'done' event to get notified when
 // the related request is done.
 type Callback struct {
-    ObjectID
-    listener CallbackListener
+	Proxy
+}
+
+// AddListener adds a listener for the events of the wl_callback and returns
+// the function that removes it again.
+func (this *Callback) AddListener(listener CallbackListener) func() {
+	return this.Proxy.AddListener(listener)
 }
 
-func (this *Callback) AddListener(listener CallbackListener) {
-    this.listener = listener
+// Subscribe returns a channel receiving the events of the wl_callback as
+// they are dispatched, until ctx is done or the object is destroyed. See
+// the Subscribe function for how events are buffered.
+func (this *Callback) Subscribe(ctx context.Context) <-chan CallbackEvent {
+	return Subscribe[CallbackEvent](&this.Proxy, ctx)
 }
 
+// Events returns the channel receiving the events of the wl_callback until it
+// is destroyed. Every call returns the same channel, use Subscribe for
+// another receiver.
+func (this *Callback) Events() <-chan CallbackEvent {
+	return Events[CallbackEvent](&this.Proxy)
+}
+
+func (this *Callback) Interface() *Interface {
+	return CallbackInterface
+}
+
+func (this *Callback) Decode(ev *Event) (func(), error) {
+	switch ev.Opcode {
+	case 0:
+		callbackData := ev.GetUint()
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(CallbackListener); ok {
+					listener.Done(callbackData)
+				}
+			}
+			this.Proxy.Publish(CallbackDoneEvent{CallbackData: callbackData})
+		}, nil
+	}
+	return nil, ev.InvalidOpcode()
+}
+
+func (this *Callback) EventFds(opcode uint16) int {
+	return 0
+}
 
+const (
+	CompositorCreateSurfaceSinceVersion = 1
+	CompositorCreateRegionSinceVersion  = 1
+)
 
 type CompositorListener interface {
 }
 
+var CompositorInterface = &Interface{
+	Name:    "wl_compositor",
+	Version: 4,
+	New:     func() Object { return &Compositor{} },
+}
+
 // A compositor.  This object is a singleton global.  The
 // compositor is in charge of combining the contents of multiple
 // surfaces into one displayable output.
 type Compositor struct {
-    ObjectID
-    listener CompositorListener
+	Proxy
+}
+
+// AddListener adds a listener for the events of the wl_compositor and returns
+// the function that removes it again.
+func (this *Compositor) AddListener(listener CompositorListener) func() {
+	return this.Proxy.AddListener(listener)
+}
+
+func (this *Compositor) Interface() *Interface {
+	return CompositorInterface
+}
+
+func (this *Compositor) Decode(ev *Event) (func(), error) {
+	return nil, ev.InvalidOpcode()
 }
 
-func (this *Compositor) AddListener(listener CompositorListener) {
-    this.listener = listener
+func (this *Compositor) EventFds(opcode uint16) int {
+	return 0
 }
 
 // Ask the compositor to create a new surface.
 func (this *Compositor) CreateSurface() (*Surface, error) {
-    return nil, nil
+	ret := &Surface{}
+	msg := NewMessage(this.ObjectID, 0)
+	msg.PutNewID(ret, this.Proxy.Version())
+	if err := this.Proxy.Send(msg); err != nil {
+		return nil, err
+	}
+	return ret, nil
 }
 
 // Ask the compositor to create a new region.
 func (this *Compositor) CreateRegion() (*Region, error) {
-    return nil, nil
+	ret := &Region{}
+	msg := NewMessage(this.ObjectID, 1)
+	msg.PutNewID(ret, this.Proxy.Version())
+	if err := this.Proxy.Send(msg); err != nil {
+		return nil, err
+	}
+	return ret, nil
 }
 
-
+const (
+	ShmPoolCreateBufferSinceVersion = 1
+	ShmPoolDestroySinceVersion      = 1
+	ShmPoolResizeSinceVersion       = 1
+)
 
 type ShmPoolListener interface {
 }
 
+var ShmPoolInterface = &Interface{
+	Name:    "wl_shm_pool",
+	Version: 1,
+	New:     func() Object { return &ShmPool{} },
+}
+
 // The wl_shm_pool object encapsulates a piece of memory shared
 // between the compositor and client.  Through the wl_shm_pool
 // object, the client can allocate shared memory wl_buffer objects.
@@ -142,36 +529,61 @@ type ShmPoolListener interface {
 // setup/teardown overhead and is useful when interactively resizing
 // a surface or for many small buffers.
 type ShmPool struct {
-    ObjectID
-    listener ShmPoolListener
+	Proxy
+}
+
+// AddListener adds a listener for the events of the wl_shm_pool and returns
+// the function that removes it again.
+func (this *ShmPool) AddListener(listener ShmPoolListener) func() {
+	return this.Proxy.AddListener(listener)
+}
+
+func (this *ShmPool) Interface() *Interface {
+	return ShmPoolInterface
+}
+
+func (this *ShmPool) Decode(ev *Event) (func(), error) {
+	return nil, ev.InvalidOpcode()
 }
 
-func (this *ShmPool) AddListener(listener ShmPoolListener) {
-    this.listener = listener
+func (this *ShmPool) EventFds(opcode uint16) int {
+	return 0
 }
 
 // Create a wl_buffer object from the pool.
-// 
+//
 // The buffer is created offset bytes into the pool and has
 // width and height as specified.  The stride argument specifies
 // the number of bytes from the beginning of one row to the beginning
 // of the next.  The format is the pixel format of the buffer and
 // must be one of those advertised through the wl_shm.format event.
-// 
+//
 // A buffer will keep a reference to the pool it was created from
 // so it is valid to destroy the pool immediately after creating
 // a buffer from it.
-func (this *ShmPool) CreateBuffer(offset int32, width int32, height int32, stride int32, format uint32) (*Buffer, error) {
-    return nil, nil
+func (this *ShmPool) CreateBuffer(offset int32, width int32, height int32, stride int32, format ShmFormat) (*Buffer, error) {
+	ret := &Buffer{}
+	msg := NewMessage(this.ObjectID, 0)
+	msg.PutNewID(ret, this.Proxy.Version())
+	msg.PutInt(offset)
+	msg.PutInt(width)
+	msg.PutInt(height)
+	msg.PutInt(stride)
+	msg.PutUint(uint32(format))
+	if err := this.Proxy.Send(msg); err != nil {
+		return nil, err
+	}
+	return ret, nil
 }
 
 // Destroy the shared memory pool.
-// 
+//
 // The mmapped memory will be released when all
 // buffers that have been created from this pool
 // are gone.
 func (this *ShmPool) Destroy() error {
-    return nil
+	msg := NewMessage(this.ObjectID, 1)
+	return this.Proxy.SendDestructor(msg)
 }
 
 // This request will cause the server to remap the backing memory
@@ -179,111 +591,382 @@ func (this *ShmPool) Destroy() error {
 // created, but using the new size.  This request can only be
 // used to make the pool bigger.
 func (this *ShmPool) Resize(size int32) error {
-    return nil
-}
-
-
-
-
-const ShmErrorInvalidFormat = 0 // buffer format is not known
-const ShmErrorInvalidStride = 1 // invalid size or stride during pool or buffer creation
-const ShmErrorInvalidFd = 2 // mmapping the file descriptor failed
-
-
-const ShmFormatArgb8888 = 0 // 32-bit ARGB format, [31:0] A:R:G:B 8:8:8:8 little endian
-const ShmFormatXrgb8888 = 1 // 32-bit RGB format, [31:0] x:R:G:B 8:8:8:8 little endian
-const ShmFormatC8 = 0x20203843 // 8-bit color index format, [7:0] C
-const ShmFormatRgb332 = 0x38424752 // 8-bit RGB format, [7:0] R:G:B 3:3:2
-const ShmFormatBgr233 = 0x38524742 // 8-bit BGR format, [7:0] B:G:R 2:3:3
-const ShmFormatXrgb4444 = 0x32315258 // 16-bit xRGB format, [15:0] x:R:G:B 4:4:4:4 little endian
-const ShmFormatXbgr4444 = 0x32314258 // 16-bit xBGR format, [15:0] x:B:G:R 4:4:4:4 little endian
-const ShmFormatRgbx4444 = 0x32315852 // 16-bit RGBx format, [15:0] R:G:B:x 4:4:4:4 little endian
-const ShmFormatBgrx4444 = 0x32315842 // 16-bit BGRx format, [15:0] B:G:R:x 4:4:4:4 little endian
-const ShmFormatArgb4444 = 0x32315241 // 16-bit ARGB format, [15:0] A:R:G:B 4:4:4:4 little endian
-const ShmFormatAbgr4444 = 0x32314241 // 16-bit ABGR format, [15:0] A:B:G:R 4:4:4:4 little endian
-const ShmFormatRgba4444 = 0x32314152 // 16-bit RBGA format, [15:0] R:G:B:A 4:4:4:4 little endian
-const ShmFormatBgra4444 = 0x32314142 // 16-bit BGRA format, [15:0] B:G:R:A 4:4:4:4 little endian
-const ShmFormatXrgb1555 = 0x35315258 // 16-bit xRGB format, [15:0] x:R:G:B 1:5:5:5 little endian
-const ShmFormatXbgr1555 = 0x35314258 // 16-bit xBGR 1555 format, [15:0] x:B:G:R 1:5:5:5 little endian
-const ShmFormatRgbx5551 = 0x35315852 // 16-bit RGBx 5551 format, [15:0] R:G:B:x 5:5:5:1 little endian
-const ShmFormatBgrx5551 = 0x35315842 // 16-bit BGRx 5551 format, [15:0] B:G:R:x 5:5:5:1 little endian
-const ShmFormatArgb1555 = 0x35315241 // 16-bit ARGB 1555 format, [15:0] A:R:G:B 1:5:5:5 little endian
-const ShmFormatAbgr1555 = 0x35314241 // 16-bit ABGR 1555 format, [15:0] A:B:G:R 1:5:5:5 little endian
-const ShmFormatRgba5551 = 0x35314152 // 16-bit RGBA 5551 format, [15:0] R:G:B:A 5:5:5:1 little endian
-const ShmFormatBgra5551 = 0x35314142 // 16-bit BGRA 5551 format, [15:0] B:G:R:A 5:5:5:1 little endian
-const ShmFormatRgb565 = 0x36314752 // 16-bit RGB 565 format, [15:0] R:G:B 5:6:5 little endian
-const ShmFormatBgr565 = 0x36314742 // 16-bit BGR 565 format, [15:0] B:G:R 5:6:5 little endian
-const ShmFormatRgb888 = 0x34324752 // 24-bit RGB format, [23:0] R:G:B little endian
-const ShmFormatBgr888 = 0x34324742 // 24-bit BGR format, [23:0] B:G:R little endian
-const ShmFormatXbgr8888 = 0x34324258 // 32-bit xBGR format, [31:0] x:B:G:R 8:8:8:8 little endian
-const ShmFormatRgbx8888 = 0x34325852 // 32-bit RGBx format, [31:0] R:G:B:x 8:8:8:8 little endian
-const ShmFormatBgrx8888 = 0x34325842 // 32-bit BGRx format, [31:0] B:G:R:x 8:8:8:8 little endian
-const ShmFormatAbgr8888 = 0x34324241 // 32-bit ABGR format, [31:0] A:B:G:R 8:8:8:8 little endian
-const ShmFormatRgba8888 = 0x34324152 // 32-bit RGBA format, [31:0] R:G:B:A 8:8:8:8 little endian
-const ShmFormatBgra8888 = 0x34324142 // 32-bit BGRA format, [31:0] B:G:R:A 8:8:8:8 little endian
-const ShmFormatXrgb2101010 = 0x30335258 // 32-bit xRGB format, [31:0] x:R:G:B 2:10:10:10 little endian
-const ShmFormatXbgr2101010 = 0x30334258 // 32-bit xBGR format, [31:0] x:B:G:R 2:10:10:10 little endian
-const ShmFormatRgbx1010102 = 0x30335852 // 32-bit RGBx format, [31:0] R:G:B:x 10:10:10:2 little endian
-const ShmFormatBgrx1010102 = 0x30335842 // 32-bit BGRx format, [31:0] B:G:R:x 10:10:10:2 little endian
-const ShmFormatArgb2101010 = 0x30335241 // 32-bit ARGB format, [31:0] A:R:G:B 2:10:10:10 little endian
-const ShmFormatAbgr2101010 = 0x30334241 // 32-bit ABGR format, [31:0] A:B:G:R 2:10:10:10 little endian
-const ShmFormatRgba1010102 = 0x30334152 // 32-bit RGBA format, [31:0] R:G:B:A 10:10:10:2 little endian
-const ShmFormatBgra1010102 = 0x30334142 // 32-bit BGRA format, [31:0] B:G:R:A 10:10:10:2 little endian
-const ShmFormatYuyv = 0x56595559 // packed YCbCr format, [31:0] Cr0:Y1:Cb0:Y0 8:8:8:8 little endian
-const ShmFormatYvyu = 0x55595659 // packed YCbCr format, [31:0] Cb0:Y1:Cr0:Y0 8:8:8:8 little endian
-const ShmFormatUyvy = 0x59565955 // packed YCbCr format, [31:0] Y1:Cr0:Y0:Cb0 8:8:8:8 little endian
-const ShmFormatVyuy = 0x59555956 // packed YCbCr format, [31:0] Y1:Cb0:Y0:Cr0 8:8:8:8 little endian
-const ShmFormatAyuv = 0x56555941 // packed AYCbCr format, [31:0] A:Y:Cb:Cr 8:8:8:8 little endian
-const ShmFormatNv12 = 0x3231564e // 2 plane YCbCr Cr:Cb format, 2x2 subsampled Cr:Cb plane
-const ShmFormatNv21 = 0x3132564e // 2 plane YCbCr Cb:Cr format, 2x2 subsampled Cb:Cr plane
-const ShmFormatNv16 = 0x3631564e // 2 plane YCbCr Cr:Cb format, 2x1 subsampled Cr:Cb plane
-const ShmFormatNv61 = 0x3136564e // 2 plane YCbCr Cb:Cr format, 2x1 subsampled Cb:Cr plane
-const ShmFormatYuv410 = 0x39565559 // 3 plane YCbCr format, 4x4 subsampled Cb (1) and Cr (2) planes
-const ShmFormatYvu410 = 0x39555659 // 3 plane YCbCr format, 4x4 subsampled Cr (1) and Cb (2) planes
-const ShmFormatYuv411 = 0x31315559 // 3 plane YCbCr format, 4x1 subsampled Cb (1) and Cr (2) planes
-const ShmFormatYvu411 = 0x31315659 // 3 plane YCbCr format, 4x1 subsampled Cr (1) and Cb (2) planes
-const ShmFormatYuv420 = 0x32315559 // 3 plane YCbCr format, 2x2 subsampled Cb (1) and Cr (2) planes
-const ShmFormatYvu420 = 0x32315659 // 3 plane YCbCr format, 2x2 subsampled Cr (1) and Cb (2) planes
-const ShmFormatYuv422 = 0x36315559 // 3 plane YCbCr format, 2x1 subsampled Cb (1) and Cr (2) planes
-const ShmFormatYvu422 = 0x36315659 // 3 plane YCbCr format, 2x1 subsampled Cr (1) and Cb (2) planes
-const ShmFormatYuv444 = 0x34325559 // 3 plane YCbCr format, non-subsampled Cb (1) and Cr (2) planes
-const ShmFormatYvu444 = 0x34325659 // 3 plane YCbCr format, non-subsampled Cr (1) and Cb (2) planes
+	msg := NewMessage(this.ObjectID, 2)
+	msg.PutInt(size)
+	return this.Proxy.Send(msg)
+}
+
+// These errors can be emitted in response to wl_shm requests.
+type ShmError uint32
+
+const ShmErrorInvalidFormat ShmError = 0 // buffer format is not known
+const ShmErrorInvalidStride ShmError = 1 // invalid size or stride during pool or buffer creation
+const ShmErrorInvalidFd ShmError = 2     // mmapping the file descriptor failed
+
+func (e ShmError) String() string {
+	switch e {
+	case ShmErrorInvalidFormat:
+		return "ShmErrorInvalidFormat"
+	case ShmErrorInvalidStride:
+		return "ShmErrorInvalidStride"
+	case ShmErrorInvalidFd:
+		return "ShmErrorInvalidFd"
+	}
+	return EnumString("ShmError", uint32(e))
+}
+
+// This describes the memory layout of an individual pixel.
+//
+// All renderers should support argb8888 and xrgb8888 but any other
+// formats are optional and may not be supported by the particular
+// renderer in use.
+//
+// The drm format codes match the macros defined in drm_fourcc.h.
+// The formats actually supported by the compositor will be
+// reported by the format event.
+type ShmFormat uint32
+
+const ShmFormatArgb8888 ShmFormat = 0             // 32-bit ARGB format, [31:0] A:R:G:B 8:8:8:8 little endian
+const ShmFormatXrgb8888 ShmFormat = 1             // 32-bit RGB format, [31:0] x:R:G:B 8:8:8:8 little endian
+const ShmFormatC8 ShmFormat = 0x20203843          // 8-bit color index format, [7:0] C
+const ShmFormatRgb332 ShmFormat = 0x38424752      // 8-bit RGB format, [7:0] R:G:B 3:3:2
+const ShmFormatBgr233 ShmFormat = 0x38524742      // 8-bit BGR format, [7:0] B:G:R 2:3:3
+const ShmFormatXrgb4444 ShmFormat = 0x32315258    // 16-bit xRGB format, [15:0] x:R:G:B 4:4:4:4 little endian
+const ShmFormatXbgr4444 ShmFormat = 0x32314258    // 16-bit xBGR format, [15:0] x:B:G:R 4:4:4:4 little endian
+const ShmFormatRgbx4444 ShmFormat = 0x32315852    // 16-bit RGBx format, [15:0] R:G:B:x 4:4:4:4 little endian
+const ShmFormatBgrx4444 ShmFormat = 0x32315842    // 16-bit BGRx format, [15:0] B:G:R:x 4:4:4:4 little endian
+const ShmFormatArgb4444 ShmFormat = 0x32315241    // 16-bit ARGB format, [15:0] A:R:G:B 4:4:4:4 little endian
+const ShmFormatAbgr4444 ShmFormat = 0x32314241    // 16-bit ABGR format, [15:0] A:B:G:R 4:4:4:4 little endian
+const ShmFormatRgba4444 ShmFormat = 0x32314152    // 16-bit RBGA format, [15:0] R:G:B:A 4:4:4:4 little endian
+const ShmFormatBgra4444 ShmFormat = 0x32314142    // 16-bit BGRA format, [15:0] B:G:R:A 4:4:4:4 little endian
+const ShmFormatXrgb1555 ShmFormat = 0x35315258    // 16-bit xRGB format, [15:0] x:R:G:B 1:5:5:5 little endian
+const ShmFormatXbgr1555 ShmFormat = 0x35314258    // 16-bit xBGR 1555 format, [15:0] x:B:G:R 1:5:5:5 little endian
+const ShmFormatRgbx5551 ShmFormat = 0x35315852    // 16-bit RGBx 5551 format, [15:0] R:G:B:x 5:5:5:1 little endian
+const ShmFormatBgrx5551 ShmFormat = 0x35315842    // 16-bit BGRx 5551 format, [15:0] B:G:R:x 5:5:5:1 little endian
+const ShmFormatArgb1555 ShmFormat = 0x35315241    // 16-bit ARGB 1555 format, [15:0] A:R:G:B 1:5:5:5 little endian
+const ShmFormatAbgr1555 ShmFormat = 0x35314241    // 16-bit ABGR 1555 format, [15:0] A:B:G:R 1:5:5:5 little endian
+const ShmFormatRgba5551 ShmFormat = 0x35314152    // 16-bit RGBA 5551 format, [15:0] R:G:B:A 5:5:5:1 little endian
+const ShmFormatBgra5551 ShmFormat = 0x35314142    // 16-bit BGRA 5551 format, [15:0] B:G:R:A 5:5:5:1 little endian
+const ShmFormatRgb565 ShmFormat = 0x36314752      // 16-bit RGB 565 format, [15:0] R:G:B 5:6:5 little endian
+const ShmFormatBgr565 ShmFormat = 0x36314742      // 16-bit BGR 565 format, [15:0] B:G:R 5:6:5 little endian
+const ShmFormatRgb888 ShmFormat = 0x34324752      // 24-bit RGB format, [23:0] R:G:B little endian
+const ShmFormatBgr888 ShmFormat = 0x34324742      // 24-bit BGR format, [23:0] B:G:R little endian
+const ShmFormatXbgr8888 ShmFormat = 0x34324258    // 32-bit xBGR format, [31:0] x:B:G:R 8:8:8:8 little endian
+const ShmFormatRgbx8888 ShmFormat = 0x34325852    // 32-bit RGBx format, [31:0] R:G:B:x 8:8:8:8 little endian
+const ShmFormatBgrx8888 ShmFormat = 0x34325842    // 32-bit BGRx format, [31:0] B:G:R:x 8:8:8:8 little endian
+const ShmFormatAbgr8888 ShmFormat = 0x34324241    // 32-bit ABGR format, [31:0] A:B:G:R 8:8:8:8 little endian
+const ShmFormatRgba8888 ShmFormat = 0x34324152    // 32-bit RGBA format, [31:0] R:G:B:A 8:8:8:8 little endian
+const ShmFormatBgra8888 ShmFormat = 0x34324142    // 32-bit BGRA format, [31:0] B:G:R:A 8:8:8:8 little endian
+const ShmFormatXrgb2101010 ShmFormat = 0x30335258 // 32-bit xRGB format, [31:0] x:R:G:B 2:10:10:10 little endian
+const ShmFormatXbgr2101010 ShmFormat = 0x30334258 // 32-bit xBGR format, [31:0] x:B:G:R 2:10:10:10 little endian
+const ShmFormatRgbx1010102 ShmFormat = 0x30335852 // 32-bit RGBx format, [31:0] R:G:B:x 10:10:10:2 little endian
+const ShmFormatBgrx1010102 ShmFormat = 0x30335842 // 32-bit BGRx format, [31:0] B:G:R:x 10:10:10:2 little endian
+const ShmFormatArgb2101010 ShmFormat = 0x30335241 // 32-bit ARGB format, [31:0] A:R:G:B 2:10:10:10 little endian
+const ShmFormatAbgr2101010 ShmFormat = 0x30334241 // 32-bit ABGR format, [31:0] A:B:G:R 2:10:10:10 little endian
+const ShmFormatRgba1010102 ShmFormat = 0x30334152 // 32-bit RGBA format, [31:0] R:G:B:A 10:10:10:2 little endian
+const ShmFormatBgra1010102 ShmFormat = 0x30334142 // 32-bit BGRA format, [31:0] B:G:R:A 10:10:10:2 little endian
+const ShmFormatYuyv ShmFormat = 0x56595559        // packed YCbCr format, [31:0] Cr0:Y1:Cb0:Y0 8:8:8:8 little endian
+const ShmFormatYvyu ShmFormat = 0x55595659        // packed YCbCr format, [31:0] Cb0:Y1:Cr0:Y0 8:8:8:8 little endian
+const ShmFormatUyvy ShmFormat = 0x59565955        // packed YCbCr format, [31:0] Y1:Cr0:Y0:Cb0 8:8:8:8 little endian
+const ShmFormatVyuy ShmFormat = 0x59555956        // packed YCbCr format, [31:0] Y1:Cb0:Y0:Cr0 8:8:8:8 little endian
+const ShmFormatAyuv ShmFormat = 0x56555941        // packed AYCbCr format, [31:0] A:Y:Cb:Cr 8:8:8:8 little endian
+const ShmFormatNv12 ShmFormat = 0x3231564e        // 2 plane YCbCr Cr:Cb format, 2x2 subsampled Cr:Cb plane
+const ShmFormatNv21 ShmFormat = 0x3132564e        // 2 plane YCbCr Cb:Cr format, 2x2 subsampled Cb:Cr plane
+const ShmFormatNv16 ShmFormat = 0x3631564e        // 2 plane YCbCr Cr:Cb format, 2x1 subsampled Cr:Cb plane
+const ShmFormatNv61 ShmFormat = 0x3136564e        // 2 plane YCbCr Cb:Cr format, 2x1 subsampled Cb:Cr plane
+const ShmFormatYuv410 ShmFormat = 0x39565559      // 3 plane YCbCr format, 4x4 subsampled Cb (1) and Cr (2) planes
+const ShmFormatYvu410 ShmFormat = 0x39555659      // 3 plane YCbCr format, 4x4 subsampled Cr (1) and Cb (2) planes
+const ShmFormatYuv411 ShmFormat = 0x31315559      // 3 plane YCbCr format, 4x1 subsampled Cb (1) and Cr (2) planes
+const ShmFormatYvu411 ShmFormat = 0x31315659      // 3 plane YCbCr format, 4x1 subsampled Cr (1) and Cb (2) planes
+const ShmFormatYuv420 ShmFormat = 0x32315559      // 3 plane YCbCr format, 2x2 subsampled Cb (1) and Cr (2) planes
+const ShmFormatYvu420 ShmFormat = 0x32315659      // 3 plane YCbCr format, 2x2 subsampled Cr (1) and Cb (2) planes
+const ShmFormatYuv422 ShmFormat = 0x36315559      // 3 plane YCbCr format, 2x1 subsampled Cb (1) and Cr (2) planes
+const ShmFormatYvu422 ShmFormat = 0x36315659      // 3 plane YCbCr format, 2x1 subsampled Cr (1) and Cb (2) planes
+const ShmFormatYuv444 ShmFormat = 0x34325559      // 3 plane YCbCr format, non-subsampled Cb (1) and Cr (2) planes
+const ShmFormatYvu444 ShmFormat = 0x34325659      // 3 plane YCbCr format, non-subsampled Cr (1) and Cb (2) planes
+
+func (e ShmFormat) String() string {
+	switch e {
+	case ShmFormatArgb8888:
+		return "ShmFormatArgb8888"
+	case ShmFormatXrgb8888:
+		return "ShmFormatXrgb8888"
+	case ShmFormatC8:
+		return "ShmFormatC8"
+	case ShmFormatRgb332:
+		return "ShmFormatRgb332"
+	case ShmFormatBgr233:
+		return "ShmFormatBgr233"
+	case ShmFormatXrgb4444:
+		return "ShmFormatXrgb4444"
+	case ShmFormatXbgr4444:
+		return "ShmFormatXbgr4444"
+	case ShmFormatRgbx4444:
+		return "ShmFormatRgbx4444"
+	case ShmFormatBgrx4444:
+		return "ShmFormatBgrx4444"
+	case ShmFormatArgb4444:
+		return "ShmFormatArgb4444"
+	case ShmFormatAbgr4444:
+		return "ShmFormatAbgr4444"
+	case ShmFormatRgba4444:
+		return "ShmFormatRgba4444"
+	case ShmFormatBgra4444:
+		return "ShmFormatBgra4444"
+	case ShmFormatXrgb1555:
+		return "ShmFormatXrgb1555"
+	case ShmFormatXbgr1555:
+		return "ShmFormatXbgr1555"
+	case ShmFormatRgbx5551:
+		return "ShmFormatRgbx5551"
+	case ShmFormatBgrx5551:
+		return "ShmFormatBgrx5551"
+	case ShmFormatArgb1555:
+		return "ShmFormatArgb1555"
+	case ShmFormatAbgr1555:
+		return "ShmFormatAbgr1555"
+	case ShmFormatRgba5551:
+		return "ShmFormatRgba5551"
+	case ShmFormatBgra5551:
+		return "ShmFormatBgra5551"
+	case ShmFormatRgb565:
+		return "ShmFormatRgb565"
+	case ShmFormatBgr565:
+		return "ShmFormatBgr565"
+	case ShmFormatRgb888:
+		return "ShmFormatRgb888"
+	case ShmFormatBgr888:
+		return "ShmFormatBgr888"
+	case ShmFormatXbgr8888:
+		return "ShmFormatXbgr8888"
+	case ShmFormatRgbx8888:
+		return "ShmFormatRgbx8888"
+	case ShmFormatBgrx8888:
+		return "ShmFormatBgrx8888"
+	case ShmFormatAbgr8888:
+		return "ShmFormatAbgr8888"
+	case ShmFormatRgba8888:
+		return "ShmFormatRgba8888"
+	case ShmFormatBgra8888:
+		return "ShmFormatBgra8888"
+	case ShmFormatXrgb2101010:
+		return "ShmFormatXrgb2101010"
+	case ShmFormatXbgr2101010:
+		return "ShmFormatXbgr2101010"
+	case ShmFormatRgbx1010102:
+		return "ShmFormatRgbx1010102"
+	case ShmFormatBgrx1010102:
+		return "ShmFormatBgrx1010102"
+	case ShmFormatArgb2101010:
+		return "ShmFormatArgb2101010"
+	case ShmFormatAbgr2101010:
+		return "ShmFormatAbgr2101010"
+	case ShmFormatRgba1010102:
+		return "ShmFormatRgba1010102"
+	case ShmFormatBgra1010102:
+		return "ShmFormatBgra1010102"
+	case ShmFormatYuyv:
+		return "ShmFormatYuyv"
+	case ShmFormatYvyu:
+		return "ShmFormatYvyu"
+	case ShmFormatUyvy:
+		return "ShmFormatUyvy"
+	case ShmFormatVyuy:
+		return "ShmFormatVyuy"
+	case ShmFormatAyuv:
+		return "ShmFormatAyuv"
+	case ShmFormatNv12:
+		return "ShmFormatNv12"
+	case ShmFormatNv21:
+		return "ShmFormatNv21"
+	case ShmFormatNv16:
+		return "ShmFormatNv16"
+	case ShmFormatNv61:
+		return "ShmFormatNv61"
+	case ShmFormatYuv410:
+		return "ShmFormatYuv410"
+	case ShmFormatYvu410:
+		return "ShmFormatYvu410"
+	case ShmFormatYuv411:
+		return "ShmFormatYuv411"
+	case ShmFormatYvu411:
+		return "ShmFormatYvu411"
+	case ShmFormatYuv420:
+		return "ShmFormatYuv420"
+	case ShmFormatYvu420:
+		return "ShmFormatYvu420"
+	case ShmFormatYuv422:
+		return "ShmFormatYuv422"
+	case ShmFormatYvu422:
+		return "ShmFormatYvu422"
+	case ShmFormatYuv444:
+		return "ShmFormatYuv444"
+	case ShmFormatYvu444:
+		return "ShmFormatYvu444"
+	}
+	return EnumString("ShmFormat", uint32(e))
+}
+
+const (
+	ShmCreatePoolSinceVersion = 1
+	ShmFormatSinceVersion     = 1
+)
 
 type ShmListener interface {
-    Format(format uint32)
+	Format(format ShmFormat)
+}
+
+// ShmListenerFuncs implements ShmListener with a function per
+// event. Events whose function is nil are ignored.
+type ShmListenerFuncs struct {
+	OnFormat func(format ShmFormat)
+}
+
+func (this ShmListenerFuncs) Format(format ShmFormat) {
+	if this.OnFormat != nil {
+		this.OnFormat(format)
+	}
+}
+
+// ShmEvent is an event of a wl_shm, one of the Shm*Event types.
+type ShmEvent interface {
+	isShmEvent()
+}
+
+// ShmFormatEvent is the wl_shm.format event.
+type ShmFormatEvent struct {
+	Format ShmFormat
+}
+
+func (ShmFormatEvent) isShmEvent() {}
+
+var ShmInterface = &Interface{
+	Name:    "wl_shm",
+	Version: 1,
+	New:     func() Object { return &Shm{} },
+	Errors: map[uint32]string{
+		uint32(ShmErrorInvalidFormat): "ShmErrorInvalidFormat",
+		uint32(ShmErrorInvalidStride): "ShmErrorInvalidStride",
+		uint32(ShmErrorInvalidFd):     "ShmErrorInvalidFd",
+	},
 }
 
 // A singleton global object that provides support for shared
 // memory.
-// 
+//
 // Clients can create wl_shm_pool objects using the create_pool
 // request.
-// 
+//
 // At connection setup time, the wl_shm object emits one or more
 // format events to inform clients about the valid pixel formats
 // that can be used for buffers.
 type Shm struct {
-    ObjectID
-    listener ShmListener
+	Proxy
 }
 
-func (this *Shm) AddListener(listener ShmListener) {
-    this.listener = listener
+// AddListener adds a listener for the events of the wl_shm and returns
+// the function that removes it again.
+func (this *Shm) AddListener(listener ShmListener) func() {
+	return this.Proxy.AddListener(listener)
+}
+
+// Subscribe returns a channel receiving the events of the wl_shm as
+// they are dispatched, until ctx is done or the object is destroyed. See
+// the Subscribe function for how events are buffered.
+func (this *Shm) Subscribe(ctx context.Context) <-chan ShmEvent {
+	return Subscribe[ShmEvent](&this.Proxy, ctx)
+}
+
+// Events returns the channel receiving the events of the wl_shm until it
+// is destroyed. Every call returns the same channel, use Subscribe for
+// another receiver.
+func (this *Shm) Events() <-chan ShmEvent {
+	return Events[ShmEvent](&this.Proxy)
+}
+
+func (this *Shm) Interface() *Interface {
+	return ShmInterface
+}
+
+func (this *Shm) Decode(ev *Event) (func(), error) {
+	switch ev.Opcode {
+	case 0:
+		format := ShmFormat(ev.GetUint())
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(ShmListener); ok {
+					listener.Format(format)
+				}
+			}
+			this.Proxy.Publish(ShmFormatEvent{Format: format})
+		}, nil
+	}
+	return nil, ev.InvalidOpcode()
+}
+
+func (this *Shm) EventFds(opcode uint16) int {
+	return 0
 }
 
 // Create a new wl_shm_pool object.
-// 
+//
 // The pool can be used to create shared memory based buffer
 // objects.  The server will mmap size bytes of the passed file
 // descriptor, to use as backing memory for the pool.
-func (this *Shm) CreatePool(size int32) (*ShmPool, error) {
-    return nil, nil
+func (this *Shm) CreatePool(fd *os.File, size int32) (*ShmPool, error) {
+	if fd == nil {
+		return nil, NullArgument("wl_shm.create_pool", "fd")
+	}
+	ret := &ShmPool{}
+	msg := NewMessage(this.ObjectID, 0)
+	msg.PutNewID(ret, this.Proxy.Version())
+	msg.PutFd(fd)
+	msg.PutInt(size)
+	if err := this.Proxy.Send(msg); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+const (
+	BufferDestroySinceVersion = 1
+	BufferReleaseSinceVersion = 1
+)
+
+type BufferListener interface {
+	Release()
+}
+
+// BufferListenerFuncs implements BufferListener with a function per
+// event. Events whose function is nil are ignored.
+type BufferListenerFuncs struct {
+	OnRelease func()
 }
 
+func (this BufferListenerFuncs) Release() {
+	if this.OnRelease != nil {
+		this.OnRelease()
+	}
+}
 
+// BufferEvent is an event of a wl_buffer, one of the Buffer*Event types.
+type BufferEvent interface {
+	isBufferEvent()
+}
 
-type BufferListener interface {
-    Release()
+// BufferReleaseEvent is the wl_buffer.release event.
+type BufferReleaseEvent struct {
+}
+
+func (BufferReleaseEvent) isBufferEvent() {}
+
+var BufferInterface = &Interface{
+	Name:    "wl_buffer",
+	Version: 1,
+	New:     func() Object { return &Buffer{} },
 }
 
 // A buffer provides the content for a wl_surface. Buffers are
@@ -292,34 +975,161 @@ type BufferListener interface {
 // wl_surface, but the mechanism by which a client provides and
 // updates the contents is defined by the buffer factory interface.
 type Buffer struct {
-    ObjectID
-    listener BufferListener
+	Proxy
+}
+
+// AddListener adds a listener for the events of the wl_buffer and returns
+// the function that removes it again.
+func (this *Buffer) AddListener(listener BufferListener) func() {
+	return this.Proxy.AddListener(listener)
+}
+
+// Subscribe returns a channel receiving the events of the wl_buffer as
+// they are dispatched, until ctx is done or the object is destroyed. See
+// the Subscribe function for how events are buffered.
+func (this *Buffer) Subscribe(ctx context.Context) <-chan BufferEvent {
+	return Subscribe[BufferEvent](&this.Proxy, ctx)
+}
+
+// Events returns the channel receiving the events of the wl_buffer until it
+// is destroyed. Every call returns the same channel, use Subscribe for
+// another receiver.
+func (this *Buffer) Events() <-chan BufferEvent {
+	return Events[BufferEvent](&this.Proxy)
+}
+
+func (this *Buffer) Interface() *Interface {
+	return BufferInterface
 }
 
-func (this *Buffer) AddListener(listener BufferListener) {
-    this.listener = listener
+func (this *Buffer) Decode(ev *Event) (func(), error) {
+	switch ev.Opcode {
+	case 0:
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(BufferListener); ok {
+					listener.Release()
+				}
+			}
+			this.Proxy.Publish(BufferReleaseEvent{})
+		}, nil
+	}
+	return nil, ev.InvalidOpcode()
+}
+
+func (this *Buffer) EventFds(opcode uint16) int {
+	return 0
 }
 
 // Destroy a buffer. If and how you need to release the backing
 // storage is defined by the buffer factory interface.
-// 
+//
 // For possible side-effects to a surface, see wl_surface.attach.
 func (this *Buffer) Destroy() error {
-    return nil
+	msg := NewMessage(this.ObjectID, 0)
+	return this.Proxy.SendDestructor(msg)
+}
+
+type DataOfferError uint32
+
+const DataOfferErrorInvalidFinish DataOfferError = 0     // finish request was called untimely
+const DataOfferErrorInvalidActionMask DataOfferError = 1 // action mask contains invalid values
+const DataOfferErrorInvalidAction DataOfferError = 2     // action argument has an invalid value
+const DataOfferErrorInvalidOffer DataOfferError = 3      // offer doesn't accept this request
+
+func (e DataOfferError) String() string {
+	switch e {
+	case DataOfferErrorInvalidFinish:
+		return "DataOfferErrorInvalidFinish"
+	case DataOfferErrorInvalidActionMask:
+		return "DataOfferErrorInvalidActionMask"
+	case DataOfferErrorInvalidAction:
+		return "DataOfferErrorInvalidAction"
+	case DataOfferErrorInvalidOffer:
+		return "DataOfferErrorInvalidOffer"
+	}
+	return EnumString("DataOfferError", uint32(e))
+}
+
+const (
+	DataOfferAcceptSinceVersion        = 1
+	DataOfferReceiveSinceVersion       = 1
+	DataOfferDestroySinceVersion       = 1
+	DataOfferFinishSinceVersion        = 3
+	DataOfferSetActionsSinceVersion    = 3
+	DataOfferOfferSinceVersion         = 1
+	DataOfferSourceActionsSinceVersion = 3
+	DataOfferActionSinceVersion        = 3
+)
+
+type DataOfferListener interface {
+	Offer(mimeType string)
+	SourceActions(sourceActions uint32)
+	Action(dndAction uint32)
 }
 
+// DataOfferListenerFuncs implements DataOfferListener with a function per
+// event. Events whose function is nil are ignored.
+type DataOfferListenerFuncs struct {
+	OnOffer         func(mimeType string)
+	OnSourceActions func(sourceActions uint32)
+	OnAction        func(dndAction uint32)
+}
 
+func (this DataOfferListenerFuncs) Offer(mimeType string) {
+	if this.OnOffer != nil {
+		this.OnOffer(mimeType)
+	}
+}
 
+func (this DataOfferListenerFuncs) SourceActions(sourceActions uint32) {
+	if this.OnSourceActions != nil {
+		this.OnSourceActions(sourceActions)
+	}
+}
 
-const DataOfferErrorInvalidFinish = 0 // finish request was called untimely
-const DataOfferErrorInvalidActionMask = 1 // action mask contains invalid values
-const DataOfferErrorInvalidAction = 2 // action argument has an invalid value
-const DataOfferErrorInvalidOffer = 3 // offer doesn't accept this request
+func (this DataOfferListenerFuncs) Action(dndAction uint32) {
+	if this.OnAction != nil {
+		this.OnAction(dndAction)
+	}
+}
 
-type DataOfferListener interface {
-    Offer(mimeType string)
-    SourceActions(sourceActions uint32)
-    Action(dndAction uint32)
+// DataOfferEvent is an event of a wl_data_offer, one of the DataOffer*Event types.
+type DataOfferEvent interface {
+	isDataOfferEvent()
+}
+
+// DataOfferOfferEvent is the wl_data_offer.offer event.
+type DataOfferOfferEvent struct {
+	MimeType string
+}
+
+func (DataOfferOfferEvent) isDataOfferEvent() {}
+
+// DataOfferSourceActionsEvent is the wl_data_offer.source_actions event.
+type DataOfferSourceActionsEvent struct {
+	SourceActions uint32
+}
+
+func (DataOfferSourceActionsEvent) isDataOfferEvent() {}
+
+// DataOfferActionEvent is the wl_data_offer.action event.
+type DataOfferActionEvent struct {
+	DndAction uint32
+}
+
+func (DataOfferActionEvent) isDataOfferEvent() {}
+
+var DataOfferInterface = &Interface{
+	Name:    "wl_data_offer",
+	Version: 3,
+	New:     func() Object { return &DataOffer{} },
+	Errors: map[uint32]string{
+		uint32(DataOfferErrorInvalidFinish):     "DataOfferErrorInvalidFinish",
+		uint32(DataOfferErrorInvalidActionMask): "DataOfferErrorInvalidActionMask",
+		uint32(DataOfferErrorInvalidAction):     "DataOfferErrorInvalidAction",
+		uint32(DataOfferErrorInvalidOffer):      "DataOfferErrorInvalidOffer",
+	},
 }
 
 // A wl_data_offer represents a piece of data offered for transfer
@@ -329,30 +1139,101 @@ type DataOfferListener interface {
 // converted to and provides the mechanism for transferring the
 // data directly from the source client.
 type DataOffer struct {
-    ObjectID
-    listener DataOfferListener
-}
-
-func (this *DataOffer) AddListener(listener DataOfferListener) {
-    this.listener = listener
+	Proxy
+}
+
+// AddListener adds a listener for the events of the wl_data_offer and returns
+// the function that removes it again.
+func (this *DataOffer) AddListener(listener DataOfferListener) func() {
+	return this.Proxy.AddListener(listener)
+}
+
+// Subscribe returns a channel receiving the events of the wl_data_offer as
+// they are dispatched, until ctx is done or the object is destroyed. See
+// the Subscribe function for how events are buffered.
+func (this *DataOffer) Subscribe(ctx context.Context) <-chan DataOfferEvent {
+	return Subscribe[DataOfferEvent](&this.Proxy, ctx)
+}
+
+// Events returns the channel receiving the events of the wl_data_offer until it
+// is destroyed. Every call returns the same channel, use Subscribe for
+// another receiver.
+func (this *DataOffer) Events() <-chan DataOfferEvent {
+	return Events[DataOfferEvent](&this.Proxy)
+}
+
+func (this *DataOffer) Interface() *Interface {
+	return DataOfferInterface
+}
+
+func (this *DataOffer) Decode(ev *Event) (func(), error) {
+	switch ev.Opcode {
+	case 0:
+		mimeType := ev.GetString()
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(DataOfferListener); ok {
+					listener.Offer(mimeType)
+				}
+			}
+			this.Proxy.Publish(DataOfferOfferEvent{MimeType: mimeType})
+		}, nil
+	case 1:
+		sourceActions := ev.GetUint()
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(DataOfferListener); ok {
+					listener.SourceActions(sourceActions)
+				}
+			}
+			this.Proxy.Publish(DataOfferSourceActionsEvent{SourceActions: sourceActions})
+		}, nil
+	case 2:
+		dndAction := ev.GetUint()
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(DataOfferListener); ok {
+					listener.Action(dndAction)
+				}
+			}
+			this.Proxy.Publish(DataOfferActionEvent{DndAction: dndAction})
+		}, nil
+	}
+	return nil, ev.InvalidOpcode()
+}
+
+func (this *DataOffer) EventFds(opcode uint16) int {
+	return 0
 }
 
 // Indicate that the client can accept the given mime type, or
 // NULL for not accepted.
-// 
+//
 // For objects of version 2 or older, this request is used by the
 // client to give feedback whether the client can receive the given
 // mime type, or NULL if none is accepted; the feedback does not
 // determine whether the drag-and-drop operation succeeds or not.
-// 
+//
 // For objects of version 3 or newer, this request determines the
 // final result of the drag-and-drop operation. If the end result
 // is that no mime types were accepted, the drag-and-drop operation
 // will be cancelled and the corresponding drag source will receive
 // wl_data_source.cancelled. Clients may still use this event in
 // conjunction with wl_data_source.action for feedback.
-func (this *DataOffer) Accept(serial uint32, mimeType string) error {
-    return nil
+func (this *DataOffer) Accept(serial uint32, mimeType *string) error {
+	msg := NewMessage(this.ObjectID, 0)
+	msg.PutUint(serial)
+	msg.PutNullString(mimeType)
+	return this.Proxy.Send(msg)
 }
 
 // To transfer the offered data, the client issues this request
@@ -361,57 +1242,68 @@ func (this *DataOffer) Accept(serial uint32, mimeType string) error {
 // with the pipe system call).  The source client writes the data
 // in the mime type representation requested and then closes the
 // file descriptor.
-// 
+//
 // The receiving client reads from the read end of the pipe until
 // EOF and then closes its end, at which point the transfer is
 // complete.
-// 
+//
 // This request may happen multiple times for different mime types,
 // both before and after wl_data_device.drop. Drag-and-drop destination
 // clients may preemptively fetch data or examine it more closely to
 // determine acceptance.
-func (this *DataOffer) Receive(mimeType string) error {
-    return nil
+func (this *DataOffer) Receive(mimeType string, fd *os.File) error {
+	if fd == nil {
+		return NullArgument("wl_data_offer.receive", "fd")
+	}
+	msg := NewMessage(this.ObjectID, 1)
+	msg.PutString(mimeType)
+	msg.PutFd(fd)
+	return this.Proxy.Send(msg)
 }
 
 // Destroy the data offer.
 func (this *DataOffer) Destroy() error {
-    return nil
+	msg := NewMessage(this.ObjectID, 2)
+	return this.Proxy.SendDestructor(msg)
 }
 
 // Notifies the compositor that the drag destination successfully
 // finished the drag-and-drop operation.
-// 
+//
 // Upon receiving this request, the compositor will emit
 // wl_data_source.dnd_finished on the drag source client.
-// 
+//
 // It is a client error to perform other requests than
 // wl_data_offer.destroy after this one. It is also an error to perform
 // this request after a NULL mime type has been set in
 // wl_data_offer.accept or no action was received through
 // wl_data_offer.action.
 func (this *DataOffer) Finish() error {
-    return nil
+	if this.Proxy.Version() < DataOfferFinishSinceVersion {
+		return UnsupportedVersion("wl_data_offer.finish", DataOfferFinishSinceVersion, this.Proxy.Version())
+	}
+	msg := NewMessage(this.ObjectID, 3)
+	return this.Proxy.Send(msg)
 }
 
 // Sets the actions that the destination side client supports for
 // this operation. This request may trigger the emission of
 // wl_data_source.action and wl_data_offer.action events if the compositor
 // needs to change the selected action.
-// 
+//
 // This request can be called multiple times throughout the
 // drag-and-drop operation, typically in response to wl_data_device.enter
 // or wl_data_device.motion events.
-// 
+//
 // This request determines the final result of the drag-and-drop
 // operation. If the end result is that no action is accepted,
 // the drag source will receive wl_drag_source.cancelled.
-// 
+//
 // The dnd_actions argument must contain only values expressed in the
 // wl_data_device_manager.dnd_actions enum, and the preferred_action
 // argument must only contain one of those values set, otherwise it
 // will result in a protocol error.
-// 
+//
 // While managing an "ask" action, the destination drag-and-drop client
 // may perform further wl_data_offer.receive requests, and is expected
 // to perform one last wl_data_offer.set_actions request with a preferred
@@ -419,29 +1311,158 @@ func (this *DataOffer) Finish() error {
 // requesting wl_data_offer.finish, in order to convey the action selected
 // by the user. If the preferred action is not in the
 // wl_data_offer.source_actions mask, an error will be raised.
-// 
+//
 // If the "ask" action is dismissed (e.g. user cancellation), the client
 // is expected to perform wl_data_offer.destroy right away.
-// 
+//
 // This request can only be made on drag-and-drop offers, a protocol error
 // will be raised otherwise.
 func (this *DataOffer) SetActions(dndActions uint32, preferredAction uint32) error {
-    return nil
+	if this.Proxy.Version() < DataOfferSetActionsSinceVersion {
+		return UnsupportedVersion("wl_data_offer.set_actions", DataOfferSetActionsSinceVersion, this.Proxy.Version())
+	}
+	msg := NewMessage(this.ObjectID, 4)
+	msg.PutUint(dndActions)
+	msg.PutUint(preferredAction)
+	return this.Proxy.Send(msg)
+}
+
+type DataSourceError uint32
+
+const DataSourceErrorInvalidActionMask DataSourceError = 0 // action mask contains invalid values
+const DataSourceErrorInvalidSource DataSourceError = 1     // source doesn't accept this request
+
+func (e DataSourceError) String() string {
+	switch e {
+	case DataSourceErrorInvalidActionMask:
+		return "DataSourceErrorInvalidActionMask"
+	case DataSourceErrorInvalidSource:
+		return "DataSourceErrorInvalidSource"
+	}
+	return EnumString("DataSourceError", uint32(e))
+}
+
+const (
+	DataSourceOfferSinceVersion            = 1
+	DataSourceDestroySinceVersion          = 1
+	DataSourceSetActionsSinceVersion       = 3
+	DataSourceTargetSinceVersion           = 1
+	DataSourceSendSinceVersion             = 1
+	DataSourceCancelledSinceVersion        = 1
+	DataSourceDndDropPerformedSinceVersion = 3
+	DataSourceDndFinishedSinceVersion      = 3
+	DataSourceActionSinceVersion           = 3
+)
+
+type DataSourceListener interface {
+	Target(mimeType *string)
+	Send(mimeType string, fd *os.File)
+	Cancelled()
+	DndDropPerformed()
+	DndFinished()
+	Action(dndAction uint32)
 }
 
+// DataSourceListenerFuncs implements DataSourceListener with a function per
+// event. Events whose function is nil are ignored.
+type DataSourceListenerFuncs struct {
+	OnTarget           func(mimeType *string)
+	OnSend             func(mimeType string, fd *os.File)
+	OnCancelled        func()
+	OnDndDropPerformed func()
+	OnDndFinished      func()
+	OnAction           func(dndAction uint32)
+}
 
+func (this DataSourceListenerFuncs) Target(mimeType *string) {
+	if this.OnTarget != nil {
+		this.OnTarget(mimeType)
+	}
+}
 
+func (this DataSourceListenerFuncs) Send(mimeType string, fd *os.File) {
+	if this.OnSend != nil {
+		this.OnSend(mimeType, fd)
+	}
+}
 
-const DataSourceErrorInvalidActionMask = 0 // action mask contains invalid values
-const DataSourceErrorInvalidSource = 1 // source doesn't accept this request
+func (this DataSourceListenerFuncs) Cancelled() {
+	if this.OnCancelled != nil {
+		this.OnCancelled()
+	}
+}
 
-type DataSourceListener interface {
-    Target(mimeType string)
-    Send(mimeType string)
-    Cancelled()
-    DndDropPerformed()
-    DndFinished()
-    Action(dndAction uint32)
+func (this DataSourceListenerFuncs) DndDropPerformed() {
+	if this.OnDndDropPerformed != nil {
+		this.OnDndDropPerformed()
+	}
+}
+
+func (this DataSourceListenerFuncs) DndFinished() {
+	if this.OnDndFinished != nil {
+		this.OnDndFinished()
+	}
+}
+
+func (this DataSourceListenerFuncs) Action(dndAction uint32) {
+	if this.OnAction != nil {
+		this.OnAction(dndAction)
+	}
+}
+
+// DataSourceEvent is an event of a wl_data_source, one of the DataSource*Event types.
+type DataSourceEvent interface {
+	isDataSourceEvent()
+}
+
+// DataSourceTargetEvent is the wl_data_source.target event.
+type DataSourceTargetEvent struct {
+	MimeType *string
+}
+
+func (DataSourceTargetEvent) isDataSourceEvent() {}
+
+// DataSourceSendEvent is the wl_data_source.send event.
+type DataSourceSendEvent struct {
+	MimeType string
+	Fd       *os.File
+}
+
+func (DataSourceSendEvent) isDataSourceEvent() {}
+
+// DataSourceCancelledEvent is the wl_data_source.cancelled event.
+type DataSourceCancelledEvent struct {
+}
+
+func (DataSourceCancelledEvent) isDataSourceEvent() {}
+
+// DataSourceDndDropPerformedEvent is the wl_data_source.dnd_drop_performed event.
+type DataSourceDndDropPerformedEvent struct {
+}
+
+func (DataSourceDndDropPerformedEvent) isDataSourceEvent() {}
+
+// DataSourceDndFinishedEvent is the wl_data_source.dnd_finished event.
+type DataSourceDndFinishedEvent struct {
+}
+
+func (DataSourceDndFinishedEvent) isDataSourceEvent() {}
+
+// DataSourceActionEvent is the wl_data_source.action event.
+type DataSourceActionEvent struct {
+	DndAction uint32
+}
+
+func (DataSourceActionEvent) isDataSourceEvent() {}
+
+var DataSourceInterface = &Interface{
+	Name:    "wl_data_source",
+	Version: 3,
+	New:     func() Object { return &DataSource{} },
+	Errors: map[uint32]string{
+		uint32(DataSourceErrorInvalidActionMask): "DataSourceErrorInvalidActionMask",
+		uint32(DataSourceErrorInvalidSource):     "DataSourceErrorInvalidSource",
+	},
 }
 
 // The wl_data_source object is the source side of a wl_data_offer.
@@ -449,84 +1470,425 @@ type DataSourceListener interface {
 // provides a way to describe the offered data and a way to respond
 // to requests to transfer the data.
 type DataSource struct {
-    ObjectID
-    listener DataSourceListener
-}
-
-func (this *DataSource) AddListener(listener DataSourceListener) {
-    this.listener = listener
+	Proxy
+}
+
+// AddListener adds a listener for the events of the wl_data_source and returns
+// the function that removes it again.
+func (this *DataSource) AddListener(listener DataSourceListener) func() {
+	return this.Proxy.AddListener(listener)
+}
+
+// Subscribe returns a channel receiving the events of the wl_data_source as
+// they are dispatched, until ctx is done or the object is destroyed. See
+// the Subscribe function for how events are buffered.
+func (this *DataSource) Subscribe(ctx context.Context) <-chan DataSourceEvent {
+	return Subscribe[DataSourceEvent](&this.Proxy, ctx)
+}
+
+// Events returns the channel receiving the events of the wl_data_source until it
+// is destroyed. Every call returns the same channel, use Subscribe for
+// another receiver.
+func (this *DataSource) Events() <-chan DataSourceEvent {
+	return Events[DataSourceEvent](&this.Proxy)
+}
+
+func (this *DataSource) Interface() *Interface {
+	return DataSourceInterface
+}
+
+func (this *DataSource) Decode(ev *Event) (func(), error) {
+	switch ev.Opcode {
+	case 0:
+		mimeType := ev.GetNullString()
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(DataSourceListener); ok {
+					listener.Target(mimeType)
+				}
+			}
+			this.Proxy.Publish(DataSourceTargetEvent{MimeType: mimeType})
+		}, nil
+	case 1:
+		mimeType := ev.GetString()
+		fd := ev.GetFd()
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(DataSourceListener); ok {
+					listener.Send(mimeType, fd)
+				}
+			}
+			this.Proxy.Publish(DataSourceSendEvent{MimeType: mimeType, Fd: fd})
+		}, nil
+	case 2:
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(DataSourceListener); ok {
+					listener.Cancelled()
+				}
+			}
+			this.Proxy.Publish(DataSourceCancelledEvent{})
+		}, nil
+	case 3:
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(DataSourceListener); ok {
+					listener.DndDropPerformed()
+				}
+			}
+			this.Proxy.Publish(DataSourceDndDropPerformedEvent{})
+		}, nil
+	case 4:
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(DataSourceListener); ok {
+					listener.DndFinished()
+				}
+			}
+			this.Proxy.Publish(DataSourceDndFinishedEvent{})
+		}, nil
+	case 5:
+		dndAction := ev.GetUint()
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(DataSourceListener); ok {
+					listener.Action(dndAction)
+				}
+			}
+			this.Proxy.Publish(DataSourceActionEvent{DndAction: dndAction})
+		}, nil
+	}
+	return nil, ev.InvalidOpcode()
+}
+
+func (this *DataSource) EventFds(opcode uint16) int {
+	if opcode == 1 {
+		return 1
+	}
+	return 0
 }
 
 // This request adds a mime type to the set of mime types
 // advertised to targets.  Can be called several times to offer
 // multiple types.
 func (this *DataSource) Offer(mimeType string) error {
-    return nil
+	msg := NewMessage(this.ObjectID, 0)
+	msg.PutString(mimeType)
+	return this.Proxy.Send(msg)
 }
 
 // Destroy the data source.
 func (this *DataSource) Destroy() error {
-    return nil
+	msg := NewMessage(this.ObjectID, 1)
+	return this.Proxy.SendDestructor(msg)
 }
 
 // Sets the actions that the source side client supports for this
 // operation. This request may trigger wl_data_source.action and
 // wl_data_offer.action events if the compositor needs to change the
 // selected action.
-// 
+//
 // The dnd_actions argument must contain only values expressed in the
 // wl_data_device_manager.dnd_actions enum, otherwise it will result
 // in a protocol error.
-// 
+//
 // This request must be made once only, and can only be made on sources
 // used in drag-and-drop, so it must be performed before
 // wl_data_device.start_drag. Attempting to use the source other than
 // for drag-and-drop will raise a protocol error.
 func (this *DataSource) SetActions(dndActions uint32) error {
-    return nil
+	if this.Proxy.Version() < DataSourceSetActionsSinceVersion {
+		return UnsupportedVersion("wl_data_source.set_actions", DataSourceSetActionsSinceVersion, this.Proxy.Version())
+	}
+	msg := NewMessage(this.ObjectID, 2)
+	msg.PutUint(dndActions)
+	return this.Proxy.Send(msg)
+}
+
+type DataDeviceError uint32
+
+const DataDeviceErrorRole DataDeviceError = 0 // given wl_surface has another role
+
+func (e DataDeviceError) String() string {
+	switch e {
+	case DataDeviceErrorRole:
+		return "DataDeviceErrorRole"
+	}
+	return EnumString("DataDeviceError", uint32(e))
+}
+
+const (
+	DataDeviceStartDragSinceVersion    = 1
+	DataDeviceSetSelectionSinceVersion = 1
+	DataDeviceReleaseSinceVersion      = 2
+	DataDeviceDataOfferSinceVersion    = 1
+	DataDeviceEnterSinceVersion        = 1
+	DataDeviceLeaveSinceVersion        = 1
+	DataDeviceMotionSinceVersion       = 1
+	DataDeviceDropSinceVersion         = 1
+	DataDeviceSelectionSinceVersion    = 1
+)
+
+type DataDeviceListener interface {
+	DataOffer(id *DataOffer)
+	Enter(serial uint32, surface *Surface, x Fixed, y Fixed, id *DataOffer)
+	Leave()
+	Motion(time uint32, x Fixed, y Fixed)
+	Drop()
+	Selection(id *DataOffer)
 }
 
+// DataDeviceListenerFuncs implements DataDeviceListener with a function per
+// event. Events whose function is nil are ignored.
+type DataDeviceListenerFuncs struct {
+	OnDataOffer func(id *DataOffer)
+	OnEnter     func(serial uint32, surface *Surface, x Fixed, y Fixed, id *DataOffer)
+	OnLeave     func()
+	OnMotion    func(time uint32, x Fixed, y Fixed)
+	OnDrop      func()
+	OnSelection func(id *DataOffer)
+}
 
+func (this DataDeviceListenerFuncs) DataOffer(id *DataOffer) {
+	if this.OnDataOffer != nil {
+		this.OnDataOffer(id)
+	}
+}
 
+func (this DataDeviceListenerFuncs) Enter(serial uint32, surface *Surface, x Fixed, y Fixed, id *DataOffer) {
+	if this.OnEnter != nil {
+		this.OnEnter(serial, surface, x, y, id)
+	}
+}
 
-const DataDeviceErrorRole = 0 // given wl_surface has another role
+func (this DataDeviceListenerFuncs) Leave() {
+	if this.OnLeave != nil {
+		this.OnLeave()
+	}
+}
 
-type DataDeviceListener interface {
-    DataOffer()
-    Enter(serial uint32, surface uint32, x uint32, y uint32, id uint32)
-    Leave()
-    Motion(time uint32, x uint32, y uint32)
-    Drop()
-    Selection(id uint32)
+func (this DataDeviceListenerFuncs) Motion(time uint32, x Fixed, y Fixed) {
+	if this.OnMotion != nil {
+		this.OnMotion(time, x, y)
+	}
+}
+
+func (this DataDeviceListenerFuncs) Drop() {
+	if this.OnDrop != nil {
+		this.OnDrop()
+	}
+}
+
+func (this DataDeviceListenerFuncs) Selection(id *DataOffer) {
+	if this.OnSelection != nil {
+		this.OnSelection(id)
+	}
+}
+
+// DataDeviceEvent is an event of a wl_data_device, one of the DataDevice*Event types.
+type DataDeviceEvent interface {
+	isDataDeviceEvent()
+}
+
+// DataDeviceDataOfferEvent is the wl_data_device.data_offer event.
+type DataDeviceDataOfferEvent struct {
+	ID *DataOffer
+}
+
+func (DataDeviceDataOfferEvent) isDataDeviceEvent() {}
+
+// DataDeviceEnterEvent is the wl_data_device.enter event.
+type DataDeviceEnterEvent struct {
+	Serial  uint32
+	Surface *Surface
+	X       Fixed
+	Y       Fixed
+	ID      *DataOffer
+}
+
+func (DataDeviceEnterEvent) isDataDeviceEvent() {}
+
+// DataDeviceLeaveEvent is the wl_data_device.leave event.
+type DataDeviceLeaveEvent struct {
+}
+
+func (DataDeviceLeaveEvent) isDataDeviceEvent() {}
+
+// DataDeviceMotionEvent is the wl_data_device.motion event.
+type DataDeviceMotionEvent struct {
+	Time uint32
+	X    Fixed
+	Y    Fixed
+}
+
+func (DataDeviceMotionEvent) isDataDeviceEvent() {}
+
+// DataDeviceDropEvent is the wl_data_device.drop event.
+type DataDeviceDropEvent struct {
+}
+
+func (DataDeviceDropEvent) isDataDeviceEvent() {}
+
+// DataDeviceSelectionEvent is the wl_data_device.selection event.
+type DataDeviceSelectionEvent struct {
+	ID *DataOffer
+}
+
+func (DataDeviceSelectionEvent) isDataDeviceEvent() {}
+
+var DataDeviceInterface = &Interface{
+	Name:    "wl_data_device",
+	Version: 3,
+	New:     func() Object { return &DataDevice{} },
+	Errors: map[uint32]string{
+		uint32(DataDeviceErrorRole): "DataDeviceErrorRole",
+	},
 }
 
 // There is one wl_data_device per seat which can be obtained
 // from the global wl_data_device_manager singleton.
-// 
+//
 // A wl_data_device provides access to inter-client data transfer
 // mechanisms such as copy-and-paste and drag-and-drop.
 type DataDevice struct {
-    ObjectID
-    listener DataDeviceListener
-}
-
-func (this *DataDevice) AddListener(listener DataDeviceListener) {
-    this.listener = listener
+	Proxy
+}
+
+// AddListener adds a listener for the events of the wl_data_device and returns
+// the function that removes it again.
+func (this *DataDevice) AddListener(listener DataDeviceListener) func() {
+	return this.Proxy.AddListener(listener)
+}
+
+// Subscribe returns a channel receiving the events of the wl_data_device as
+// they are dispatched, until ctx is done or the object is destroyed. See
+// the Subscribe function for how events are buffered.
+func (this *DataDevice) Subscribe(ctx context.Context) <-chan DataDeviceEvent {
+	return Subscribe[DataDeviceEvent](&this.Proxy, ctx)
+}
+
+// Events returns the channel receiving the events of the wl_data_device until it
+// is destroyed. Every call returns the same channel, use Subscribe for
+// another receiver.
+func (this *DataDevice) Events() <-chan DataDeviceEvent {
+	return Events[DataDeviceEvent](&this.Proxy)
+}
+
+func (this *DataDevice) Interface() *Interface {
+	return DataDeviceInterface
+}
+
+func (this *DataDevice) Decode(ev *Event) (func(), error) {
+	switch ev.Opcode {
+	case 0:
+		id := &DataOffer{Proxy: Proxy{ObjectID: ev.GetObject()}}
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		if err := this.Proxy.Client().AddServerProxy(id, &this.Proxy); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(DataDeviceListener); ok {
+					listener.DataOffer(id)
+				}
+			}
+			this.Proxy.Publish(DataDeviceDataOfferEvent{ID: id})
+		}, nil
+	case 1:
+		serial := ev.GetUint()
+		surface, _ := ev.GetProxy(this.Proxy.Client()).(*Surface)
+		x := ev.GetFixed()
+		y := ev.GetFixed()
+		id, _ := ev.GetProxy(this.Proxy.Client()).(*DataOffer)
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(DataDeviceListener); ok {
+					listener.Enter(serial, surface, x, y, id)
+				}
+			}
+			this.Proxy.Publish(DataDeviceEnterEvent{Serial: serial, Surface: surface, X: x, Y: y, ID: id})
+		}, nil
+	case 2:
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(DataDeviceListener); ok {
+					listener.Leave()
+				}
+			}
+			this.Proxy.Publish(DataDeviceLeaveEvent{})
+		}, nil
+	case 3:
+		time := ev.GetUint()
+		x := ev.GetFixed()
+		y := ev.GetFixed()
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(DataDeviceListener); ok {
+					listener.Motion(time, x, y)
+				}
+			}
+			this.Proxy.Publish(DataDeviceMotionEvent{Time: time, X: x, Y: y})
+		}, nil
+	case 4:
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(DataDeviceListener); ok {
+					listener.Drop()
+				}
+			}
+			this.Proxy.Publish(DataDeviceDropEvent{})
+		}, nil
+	case 5:
+		id, _ := ev.GetProxy(this.Proxy.Client()).(*DataOffer)
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(DataDeviceListener); ok {
+					listener.Selection(id)
+				}
+			}
+			this.Proxy.Publish(DataDeviceSelectionEvent{ID: id})
+		}, nil
+	}
+	return nil, ev.InvalidOpcode()
+}
+
+func (this *DataDevice) EventFds(opcode uint16) int {
+	return 0
 }
 
 // This request asks the compositor to start a drag-and-drop
 // operation on behalf of the client.
-// 
+//
 // The source argument is the data source that provides the data
 // for the eventual data transfer. If source is NULL, enter, leave
 // and motion events are sent only to the client that initiated the
 // drag and the client is expected to handle the data passing
 // internally.
-// 
+//
 // The origin surface is the surface where the drag originates and
 // the client must have an active implicit grab that matches the
 // serial.
-// 
+//
 // The icon surface is an optional (can be NULL) surface that
 // provides an icon to be moved around with the cursor.  Initially,
 // the top-left corner of the icon surface is placed at the cursor
@@ -535,312 +1897,811 @@ func (this *DataDevice) AddListener(listener DataDeviceListener) {
 // wl_surface.commit as usual. The icon surface is given the role of
 // a drag-and-drop icon. If the icon surface already has another role,
 // it raises a protocol error.
-// 
+//
 // The current and pending input regions of the icon wl_surface are
 // cleared, and wl_surface.set_input_region is ignored until the
 // wl_surface is no longer used as the icon surface. When the use
 // as an icon ends, the current and pending input regions become
 // undefined, and the wl_surface is unmapped.
-func (this *DataDevice) StartDrag(source uint32, origin uint32, icon uint32, serial uint32) error {
-    return nil
+func (this *DataDevice) StartDrag(source *DataSource, origin *Surface, icon *Surface, serial uint32) error {
+	if origin == nil {
+		return NullArgument("wl_data_device.start_drag", "origin")
+	}
+	msg := NewMessage(this.ObjectID, 0)
+	if source == nil {
+		msg.PutObject(0)
+	} else {
+		msg.PutObject(source.ObjectID)
+	}
+	msg.PutObject(origin.ObjectID)
+	if icon == nil {
+		msg.PutObject(0)
+	} else {
+		msg.PutObject(icon.ObjectID)
+	}
+	msg.PutUint(serial)
+	return this.Proxy.Send(msg)
 }
 
 // This request asks the compositor to set the selection
 // to the data from the source on behalf of the client.
-// 
+//
 // To unset the selection, set the source to NULL.
-func (this *DataDevice) SetSelection(source uint32, serial uint32) error {
-    return nil
+func (this *DataDevice) SetSelection(source *DataSource, serial uint32) error {
+	msg := NewMessage(this.ObjectID, 1)
+	if source == nil {
+		msg.PutObject(0)
+	} else {
+		msg.PutObject(source.ObjectID)
+	}
+	msg.PutUint(serial)
+	return this.Proxy.Send(msg)
 }
 
 // This request destroys the data device.
 func (this *DataDevice) Release() error {
-    return nil
-}
-
-
-
-
-const DataDeviceManagerDndActionNone = 0 // no action
-const DataDeviceManagerDndActionCopy = 1 // copy action
-const DataDeviceManagerDndActionMove = 2 // move action
-const DataDeviceManagerDndActionAsk = 4 // ask action
+	if this.Proxy.Version() < DataDeviceReleaseSinceVersion {
+		return UnsupportedVersion("wl_data_device.release", DataDeviceReleaseSinceVersion, this.Proxy.Version())
+	}
+	msg := NewMessage(this.ObjectID, 2)
+	return this.Proxy.SendDestructor(msg)
+}
+
+// This is a bitmask of the available/preferred actions in a
+// drag-and-drop operation.
+//
+// In the compositor, the selected action is a result of matching the
+// actions offered by the source and destination sides.  "action" events
+// with a "none" action will be sent to both source and destination if
+// there is no match. All further checks will effectively happen on
+// (source actions ∩ destination actions).
+//
+// In addition, compositors may also pick different actions in
+// reaction to key modifiers being pressed. One common design that
+// is used in major toolkits (and the behavior recommended for
+// compositors) is:
+//
+// - If no modifiers are pressed, the first match (in bit order)
+// will be used.
+// - Pressing Shift selects "move", if enabled in the mask.
+// - Pressing Control selects "copy", if enabled in the mask.
+//
+// Behavior beyond that is considered implementation-dependent.
+// Compositors may for example bind other modifiers (like Alt/Meta)
+// or drags initiated with other buttons than BTN_LEFT to specific
+// actions (e.g. "ask").
+type DataDeviceManagerDndAction uint32
+
+const DataDeviceManagerDndActionNone DataDeviceManagerDndAction = 0 // no action
+const DataDeviceManagerDndActionCopy DataDeviceManagerDndAction = 1 // copy action
+const DataDeviceManagerDndActionMove DataDeviceManagerDndAction = 2 // move action
+const DataDeviceManagerDndActionAsk DataDeviceManagerDndAction = 4  // ask action
+
+func (e DataDeviceManagerDndAction) String() string {
+	return BitfieldString(uint32(e), []EnumEntry{
+		{uint32(DataDeviceManagerDndActionNone), "DataDeviceManagerDndActionNone"},
+		{uint32(DataDeviceManagerDndActionCopy), "DataDeviceManagerDndActionCopy"},
+		{uint32(DataDeviceManagerDndActionMove), "DataDeviceManagerDndActionMove"},
+		{uint32(DataDeviceManagerDndActionAsk), "DataDeviceManagerDndActionAsk"},
+	})
+}
+
+// Has reports whether every flag set in flag is also set in e.
+func (e DataDeviceManagerDndAction) Has(flag DataDeviceManagerDndAction) bool {
+	return e&flag == flag
+}
+
+// Set returns e with the flags in flag set.
+func (e DataDeviceManagerDndAction) Set(flag DataDeviceManagerDndAction) DataDeviceManagerDndAction {
+	return e | flag
+}
+
+// Clear returns e with the flags in flag cleared.
+func (e DataDeviceManagerDndAction) Clear(flag DataDeviceManagerDndAction) DataDeviceManagerDndAction {
+	return e &^ flag
+}
+
+const (
+	DataDeviceManagerCreateDataSourceSinceVersion = 1
+	DataDeviceManagerGetDataDeviceSinceVersion    = 1
+)
 
 type DataDeviceManagerListener interface {
 }
 
+var DataDeviceManagerInterface = &Interface{
+	Name:    "wl_data_device_manager",
+	Version: 3,
+	New:     func() Object { return &DataDeviceManager{} },
+}
+
 // The wl_data_device_manager is a singleton global object that
 // provides access to inter-client data transfer mechanisms such as
 // copy-and-paste and drag-and-drop.  These mechanisms are tied to
 // a wl_seat and this interface lets a client get a wl_data_device
 // corresponding to a wl_seat.
-// 
+//
 // Depending on the version bound, the objects created from the bound
 // wl_data_device_manager object will have different requirements for
 // functioning properly. See wl_data_source.set_actions,
 // wl_data_offer.accept and wl_data_offer.finish for details.
 type DataDeviceManager struct {
-    ObjectID
-    listener DataDeviceManagerListener
+	Proxy
 }
 
-func (this *DataDeviceManager) AddListener(listener DataDeviceManagerListener) {
-    this.listener = listener
+// AddListener adds a listener for the events of the wl_data_device_manager and returns
+// the function that removes it again.
+func (this *DataDeviceManager) AddListener(listener DataDeviceManagerListener) func() {
+	return this.Proxy.AddListener(listener)
+}
+
+func (this *DataDeviceManager) Interface() *Interface {
+	return DataDeviceManagerInterface
+}
+
+func (this *DataDeviceManager) Decode(ev *Event) (func(), error) {
+	return nil, ev.InvalidOpcode()
+}
+
+func (this *DataDeviceManager) EventFds(opcode uint16) int {
+	return 0
 }
 
 // Create a new data source.
 func (this *DataDeviceManager) CreateDataSource() (*DataSource, error) {
-    return nil, nil
+	ret := &DataSource{}
+	msg := NewMessage(this.ObjectID, 0)
+	msg.PutNewID(ret, this.Proxy.Version())
+	if err := this.Proxy.Send(msg); err != nil {
+		return nil, err
+	}
+	return ret, nil
 }
 
 // Create a new data device for a given seat.
-func (this *DataDeviceManager) GetDataDevice(seat uint32) (*DataDevice, error) {
-    return nil, nil
+func (this *DataDeviceManager) GetDataDevice(seat *Seat) (*DataDevice, error) {
+	if seat == nil {
+		return nil, NullArgument("wl_data_device_manager.get_data_device", "seat")
+	}
+	ret := &DataDevice{}
+	msg := NewMessage(this.ObjectID, 1)
+	msg.PutNewID(ret, this.Proxy.Version())
+	msg.PutObject(seat.ObjectID)
+	if err := this.Proxy.Send(msg); err != nil {
+		return nil, err
+	}
+	return ret, nil
 }
 
+type ShellError uint32
 
+const ShellErrorRole ShellError = 0 // given wl_surface has another role
 
+func (e ShellError) String() string {
+	switch e {
+	case ShellErrorRole:
+		return "ShellErrorRole"
+	}
+	return EnumString("ShellError", uint32(e))
+}
 
-const ShellErrorRole = 0 // given wl_surface has another role
+const (
+	ShellGetShellSurfaceSinceVersion = 1
+)
 
 type ShellListener interface {
 }
 
+var ShellInterface = &Interface{
+	Name:    "wl_shell",
+	Version: 1,
+	New:     func() Object { return &Shell{} },
+	Errors: map[uint32]string{
+		uint32(ShellErrorRole): "ShellErrorRole",
+	},
+}
+
 // This interface is implemented by servers that provide
 // desktop-style user interfaces.
-// 
+//
 // It allows clients to associate a wl_shell_surface with
 // a basic surface.
 type Shell struct {
-    ObjectID
-    listener ShellListener
+	Proxy
+}
+
+// AddListener adds a listener for the events of the wl_shell and returns
+// the function that removes it again.
+func (this *Shell) AddListener(listener ShellListener) func() {
+	return this.Proxy.AddListener(listener)
 }
 
-func (this *Shell) AddListener(listener ShellListener) {
-    this.listener = listener
+func (this *Shell) Interface() *Interface {
+	return ShellInterface
+}
+
+func (this *Shell) Decode(ev *Event) (func(), error) {
+	return nil, ev.InvalidOpcode()
+}
+
+func (this *Shell) EventFds(opcode uint16) int {
+	return 0
 }
 
 // Create a shell surface for an existing surface. This gives
 // the wl_surface the role of a shell surface. If the wl_surface
 // already has another role, it raises a protocol error.
-// 
+//
 // Only one shell surface can be associated with a given surface.
-func (this *Shell) GetShellSurface(surface uint32) (*ShellSurface, error) {
-    return nil, nil
+func (this *Shell) GetShellSurface(surface *Surface) (*ShellSurface, error) {
+	if surface == nil {
+		return nil, NullArgument("wl_shell.get_shell_surface", "surface")
+	}
+	ret := &ShellSurface{}
+	msg := NewMessage(this.ObjectID, 0)
+	msg.PutNewID(ret, this.Proxy.Version())
+	msg.PutObject(surface.ObjectID)
+	if err := this.Proxy.Send(msg); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// These values are used to indicate which edge of a surface
+// is being dragged in a resize operation. The server may
+// use this information to adapt its behavior, e.g. choose
+// an appropriate cursor image.
+type ShellSurfaceResize uint32
+
+const ShellSurfaceResizeNone ShellSurfaceResize = 0         // no edge
+const ShellSurfaceResizeTop ShellSurfaceResize = 1          // top edge
+const ShellSurfaceResizeBottom ShellSurfaceResize = 2       // bottom edge
+const ShellSurfaceResizeLeft ShellSurfaceResize = 4         // left edge
+const ShellSurfaceResizeTopLeft ShellSurfaceResize = 5      // top and left edges
+const ShellSurfaceResizeBottomLeft ShellSurfaceResize = 6   // bottom and left edges
+const ShellSurfaceResizeRight ShellSurfaceResize = 8        // right edge
+const ShellSurfaceResizeTopRight ShellSurfaceResize = 9     // top and right edges
+const ShellSurfaceResizeBottomRight ShellSurfaceResize = 10 // bottom and right edges
+
+func (e ShellSurfaceResize) String() string {
+	return BitfieldString(uint32(e), []EnumEntry{
+		{uint32(ShellSurfaceResizeNone), "ShellSurfaceResizeNone"},
+		{uint32(ShellSurfaceResizeTop), "ShellSurfaceResizeTop"},
+		{uint32(ShellSurfaceResizeBottom), "ShellSurfaceResizeBottom"},
+		{uint32(ShellSurfaceResizeLeft), "ShellSurfaceResizeLeft"},
+		{uint32(ShellSurfaceResizeTopLeft), "ShellSurfaceResizeTopLeft"},
+		{uint32(ShellSurfaceResizeBottomLeft), "ShellSurfaceResizeBottomLeft"},
+		{uint32(ShellSurfaceResizeRight), "ShellSurfaceResizeRight"},
+		{uint32(ShellSurfaceResizeTopRight), "ShellSurfaceResizeTopRight"},
+		{uint32(ShellSurfaceResizeBottomRight), "ShellSurfaceResizeBottomRight"},
+	})
+}
+
+// Has reports whether every flag set in flag is also set in e.
+func (e ShellSurfaceResize) Has(flag ShellSurfaceResize) bool {
+	return e&flag == flag
+}
+
+// Set returns e with the flags in flag set.
+func (e ShellSurfaceResize) Set(flag ShellSurfaceResize) ShellSurfaceResize {
+	return e | flag
+}
+
+// Clear returns e with the flags in flag cleared.
+func (e ShellSurfaceResize) Clear(flag ShellSurfaceResize) ShellSurfaceResize {
+	return e &^ flag
+}
+
+// These flags specify details of the expected behaviour
+// of transient surfaces. Used in the set_transient request.
+type ShellSurfaceTransient uint32
+
+const ShellSurfaceTransientInactive ShellSurfaceTransient = 0x1 // do not set keyboard focus
+
+func (e ShellSurfaceTransient) String() string {
+	return BitfieldString(uint32(e), []EnumEntry{
+		{uint32(ShellSurfaceTransientInactive), "ShellSurfaceTransientInactive"},
+	})
+}
+
+// Has reports whether every flag set in flag is also set in e.
+func (e ShellSurfaceTransient) Has(flag ShellSurfaceTransient) bool {
+	return e&flag == flag
+}
+
+// Set returns e with the flags in flag set.
+func (e ShellSurfaceTransient) Set(flag ShellSurfaceTransient) ShellSurfaceTransient {
+	return e | flag
+}
+
+// Clear returns e with the flags in flag cleared.
+func (e ShellSurfaceTransient) Clear(flag ShellSurfaceTransient) ShellSurfaceTransient {
+	return e &^ flag
+}
+
+// Hints to indicate to the compositor how to deal with a conflict
+// between the dimensions of the surface and the dimensions of the
+// output. The compositor is free to ignore this parameter.
+type ShellSurfaceFullscreenMethod uint32
+
+const ShellSurfaceFullscreenMethodDefault ShellSurfaceFullscreenMethod = 0 // no preference, apply default policy
+const ShellSurfaceFullscreenMethodScale ShellSurfaceFullscreenMethod = 1   // scale, preserve the surface's aspect ratio and center on output
+const ShellSurfaceFullscreenMethodDriver ShellSurfaceFullscreenMethod = 2  // switch output mode to the smallest mode that can fit the surface, add black borders to compensate size mismatch
+const ShellSurfaceFullscreenMethodFill ShellSurfaceFullscreenMethod = 3    // no upscaling, center on output and add black borders to compensate size mismatch
+
+func (e ShellSurfaceFullscreenMethod) String() string {
+	switch e {
+	case ShellSurfaceFullscreenMethodDefault:
+		return "ShellSurfaceFullscreenMethodDefault"
+	case ShellSurfaceFullscreenMethodScale:
+		return "ShellSurfaceFullscreenMethodScale"
+	case ShellSurfaceFullscreenMethodDriver:
+		return "ShellSurfaceFullscreenMethodDriver"
+	case ShellSurfaceFullscreenMethodFill:
+		return "ShellSurfaceFullscreenMethodFill"
+	}
+	return EnumString("ShellSurfaceFullscreenMethod", uint32(e))
+}
+
+const (
+	ShellSurfacePongSinceVersion          = 1
+	ShellSurfaceMoveSinceVersion          = 1
+	ShellSurfaceResizeSinceVersion        = 1
+	ShellSurfaceSetToplevelSinceVersion   = 1
+	ShellSurfaceSetTransientSinceVersion  = 1
+	ShellSurfaceSetFullscreenSinceVersion = 1
+	ShellSurfaceSetPopupSinceVersion      = 1
+	ShellSurfaceSetMaximizedSinceVersion  = 1
+	ShellSurfaceSetTitleSinceVersion      = 1
+	ShellSurfaceSetClassSinceVersion      = 1
+	ShellSurfacePingSinceVersion          = 1
+	ShellSurfaceConfigureSinceVersion     = 1
+	ShellSurfacePopupDoneSinceVersion     = 1
+)
+
+type ShellSurfaceListener interface {
+	Ping(serial uint32)
+	Configure(edges ShellSurfaceResize, width int32, height int32)
+	PopupDone()
 }
 
+// ShellSurfaceListenerFuncs implements ShellSurfaceListener with a function per
+// event. Events whose function is nil are ignored.
+type ShellSurfaceListenerFuncs struct {
+	OnPing      func(serial uint32)
+	OnConfigure func(edges ShellSurfaceResize, width int32, height int32)
+	OnPopupDone func()
+}
 
+func (this ShellSurfaceListenerFuncs) Ping(serial uint32) {
+	if this.OnPing != nil {
+		this.OnPing(serial)
+	}
+}
 
+func (this ShellSurfaceListenerFuncs) Configure(edges ShellSurfaceResize, width int32, height int32) {
+	if this.OnConfigure != nil {
+		this.OnConfigure(edges, width, height)
+	}
+}
 
-const ShellSurfaceResizeNone = 0 // no edge
-const ShellSurfaceResizeTop = 1 // top edge
-const ShellSurfaceResizeBottom = 2 // bottom edge
-const ShellSurfaceResizeLeft = 4 // left edge
-const ShellSurfaceResizeTopLeft = 5 // top and left edges
-const ShellSurfaceResizeBottomLeft = 6 // bottom and left edges
-const ShellSurfaceResizeRight = 8 // right edge
-const ShellSurfaceResizeTopRight = 9 // top and right edges
-const ShellSurfaceResizeBottomRight = 10 // bottom and right edges
+func (this ShellSurfaceListenerFuncs) PopupDone() {
+	if this.OnPopupDone != nil {
+		this.OnPopupDone()
+	}
+}
 
+// ShellSurfaceEvent is an event of a wl_shell_surface, one of the ShellSurface*Event types.
+type ShellSurfaceEvent interface {
+	isShellSurfaceEvent()
+}
 
-const ShellSurfaceTransientInactive = 0x1 // do not set keyboard focus
+// ShellSurfacePingEvent is the wl_shell_surface.ping event.
+type ShellSurfacePingEvent struct {
+	Serial uint32
+}
+
+func (ShellSurfacePingEvent) isShellSurfaceEvent() {}
 
+// ShellSurfaceConfigureEvent is the wl_shell_surface.configure event.
+type ShellSurfaceConfigureEvent struct {
+	Edges  ShellSurfaceResize
+	Width  int32
+	Height int32
+}
 
-const ShellSurfaceFullscreenMethodDefault = 0 // no preference, apply default policy
-const ShellSurfaceFullscreenMethodScale = 1 // scale, preserve the surface's aspect ratio and center on output
-const ShellSurfaceFullscreenMethodDriver = 2 // switch output mode to the smallest mode that can fit the surface, add black borders to compensate size mismatch
-const ShellSurfaceFullscreenMethodFill = 3 // no upscaling, center on output and add black borders to compensate size mismatch
+func (ShellSurfaceConfigureEvent) isShellSurfaceEvent() {}
 
-type ShellSurfaceListener interface {
-    Ping(serial uint32)
-    Configure(edges uint32, width int32, height int32)
-    PopupDone()
+// ShellSurfacePopupDoneEvent is the wl_shell_surface.popup_done event.
+type ShellSurfacePopupDoneEvent struct {
+}
+
+func (ShellSurfacePopupDoneEvent) isShellSurfaceEvent() {}
+
+var ShellSurfaceInterface = &Interface{
+	Name:    "wl_shell_surface",
+	Version: 1,
+	New:     func() Object { return &ShellSurface{} },
 }
 
 // An interface that may be implemented by a wl_surface, for
 // implementations that provide a desktop-style user interface.
-// 
+//
 // It provides requests to treat surfaces like toplevel, fullscreen
 // or popup windows, move, resize or maximize them, associate
 // metadata like title and class, etc.
-// 
+//
 // On the server side the object is automatically destroyed when
 // the related wl_surface is destroyed. On the client side,
 // wl_shell_surface_destroy() must be called before destroying
 // the wl_surface object.
 type ShellSurface struct {
-    ObjectID
-    listener ShellSurfaceListener
-}
-
-func (this *ShellSurface) AddListener(listener ShellSurfaceListener) {
-    this.listener = listener
+	Proxy
+}
+
+// AddListener adds a listener for the events of the wl_shell_surface and returns
+// the function that removes it again.
+func (this *ShellSurface) AddListener(listener ShellSurfaceListener) func() {
+	return this.Proxy.AddListener(listener)
+}
+
+// Subscribe returns a channel receiving the events of the wl_shell_surface as
+// they are dispatched, until ctx is done or the object is destroyed. See
+// the Subscribe function for how events are buffered.
+func (this *ShellSurface) Subscribe(ctx context.Context) <-chan ShellSurfaceEvent {
+	return Subscribe[ShellSurfaceEvent](&this.Proxy, ctx)
+}
+
+// Events returns the channel receiving the events of the wl_shell_surface until it
+// is destroyed. Every call returns the same channel, use Subscribe for
+// another receiver.
+func (this *ShellSurface) Events() <-chan ShellSurfaceEvent {
+	return Events[ShellSurfaceEvent](&this.Proxy)
+}
+
+func (this *ShellSurface) Interface() *Interface {
+	return ShellSurfaceInterface
+}
+
+func (this *ShellSurface) Decode(ev *Event) (func(), error) {
+	switch ev.Opcode {
+	case 0:
+		serial := ev.GetUint()
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(ShellSurfaceListener); ok {
+					listener.Ping(serial)
+				}
+			}
+			this.Proxy.Publish(ShellSurfacePingEvent{Serial: serial})
+		}, nil
+	case 1:
+		edges := ShellSurfaceResize(ev.GetUint())
+		width := ev.GetInt()
+		height := ev.GetInt()
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(ShellSurfaceListener); ok {
+					listener.Configure(edges, width, height)
+				}
+			}
+			this.Proxy.Publish(ShellSurfaceConfigureEvent{Edges: edges, Width: width, Height: height})
+		}, nil
+	case 2:
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(ShellSurfaceListener); ok {
+					listener.PopupDone()
+				}
+			}
+			this.Proxy.Publish(ShellSurfacePopupDoneEvent{})
+		}, nil
+	}
+	return nil, ev.InvalidOpcode()
+}
+
+func (this *ShellSurface) EventFds(opcode uint16) int {
+	return 0
 }
 
 // A client must respond to a ping event with a pong request or
 // the client may be deemed unresponsive.
 func (this *ShellSurface) Pong(serial uint32) error {
-    return nil
+	msg := NewMessage(this.ObjectID, 0)
+	msg.PutUint(serial)
+	return this.Proxy.Send(msg)
 }
 
 // Start a pointer-driven move of the surface.
-// 
+//
 // This request must be used in response to a button press event.
 // The server may ignore move requests depending on the state of
 // the surface (e.g. fullscreen or maximized).
-func (this *ShellSurface) Move(seat uint32, serial uint32) error {
-    return nil
+func (this *ShellSurface) Move(seat *Seat, serial uint32) error {
+	if seat == nil {
+		return NullArgument("wl_shell_surface.move", "seat")
+	}
+	msg := NewMessage(this.ObjectID, 1)
+	msg.PutObject(seat.ObjectID)
+	msg.PutUint(serial)
+	return this.Proxy.Send(msg)
 }
 
 // Start a pointer-driven resizing of the surface.
-// 
+//
 // This request must be used in response to a button press event.
 // The server may ignore resize requests depending on the state of
 // the surface (e.g. fullscreen or maximized).
-func (this *ShellSurface) Resize(seat uint32, serial uint32, edges uint32) error {
-    return nil
+func (this *ShellSurface) Resize(seat *Seat, serial uint32, edges ShellSurfaceResize) error {
+	if seat == nil {
+		return NullArgument("wl_shell_surface.resize", "seat")
+	}
+	msg := NewMessage(this.ObjectID, 2)
+	msg.PutObject(seat.ObjectID)
+	msg.PutUint(serial)
+	msg.PutUint(uint32(edges))
+	return this.Proxy.Send(msg)
 }
 
 // Map the surface as a toplevel surface.
-// 
+//
 // A toplevel surface is not fullscreen, maximized or transient.
 func (this *ShellSurface) SetToplevel() error {
-    return nil
+	msg := NewMessage(this.ObjectID, 3)
+	return this.Proxy.Send(msg)
 }
 
 // Map the surface relative to an existing surface.
-// 
+//
 // The x and y arguments specify the location of the upper left
 // corner of the surface relative to the upper left corner of the
 // parent surface, in surface-local coordinates.
-// 
+//
 // The flags argument controls details of the transient behaviour.
-func (this *ShellSurface) SetTransient(parent uint32, x int32, y int32, flags uint32) error {
-    return nil
+func (this *ShellSurface) SetTransient(parent *Surface, x int32, y int32, flags ShellSurfaceTransient) error {
+	if parent == nil {
+		return NullArgument("wl_shell_surface.set_transient", "parent")
+	}
+	msg := NewMessage(this.ObjectID, 4)
+	msg.PutObject(parent.ObjectID)
+	msg.PutInt(x)
+	msg.PutInt(y)
+	msg.PutUint(uint32(flags))
+	return this.Proxy.Send(msg)
 }
 
 // Map the surface as a fullscreen surface.
-// 
+//
 // If an output parameter is given then the surface will be made
 // fullscreen on that output. If the client does not specify the
 // output then the compositor will apply its policy - usually
 // choosing the output on which the surface has the biggest surface
 // area.
-// 
+//
 // The client may specify a method to resolve a size conflict
 // between the output size and the surface size - this is provided
 // through the method parameter.
-// 
+//
 // The framerate parameter is used only when the method is set
 // to "driver", to indicate the preferred framerate. A value of 0
 // indicates that the client does not care about framerate.  The
 // framerate is specified in mHz, that is framerate of 60000 is 60Hz.
-// 
+//
 // A method of "scale" or "driver" implies a scaling operation of
 // the surface, either via a direct scaling operation or a change of
 // the output mode. This will override any kind of output scaling, so
 // that mapping a surface with a buffer size equal to the mode can
 // fill the screen independent of buffer_scale.
-// 
+//
 // A method of "fill" means we don't scale up the buffer, however
 // any output scale is applied. This means that you may run into
 // an edge case where the application maps a buffer with the same
 // size of the output mode but buffer_scale 1 (thus making a
 // surface larger than the output). In this case it is allowed to
 // downscale the results to fit the screen.
-// 
+//
 // The compositor must reply to this request with a configure event
 // with the dimensions for the output on which the surface will
 // be made fullscreen.
-func (this *ShellSurface) SetFullscreen(method uint32, framerate uint32, output uint32) error {
-    return nil
+func (this *ShellSurface) SetFullscreen(method ShellSurfaceFullscreenMethod, framerate uint32, output *Output) error {
+	msg := NewMessage(this.ObjectID, 5)
+	msg.PutUint(uint32(method))
+	msg.PutUint(framerate)
+	if output == nil {
+		msg.PutObject(0)
+	} else {
+		msg.PutObject(output.ObjectID)
+	}
+	return this.Proxy.Send(msg)
 }
 
 // Map the surface as a popup.
-// 
+//
 // A popup surface is a transient surface with an added pointer
 // grab.
-// 
+//
 // An existing implicit grab will be changed to owner-events mode,
 // and the popup grab will continue after the implicit grab ends
 // (i.e. releasing the mouse button does not cause the popup to
 // be unmapped).
-// 
+//
 // The popup grab continues until the window is destroyed or a
 // mouse button is pressed in any other client's window. A click
 // in any of the client's surfaces is reported as normal, however,
 // clicks in other clients' surfaces will be discarded and trigger
 // the callback.
-// 
+//
 // The x and y arguments specify the location of the upper left
 // corner of the surface relative to the upper left corner of the
 // parent surface, in surface-local coordinates.
-func (this *ShellSurface) SetPopup(seat uint32, serial uint32, parent uint32, x int32, y int32, flags uint32) error {
-    return nil
+func (this *ShellSurface) SetPopup(seat *Seat, serial uint32, parent *Surface, x int32, y int32, flags ShellSurfaceTransient) error {
+	if seat == nil {
+		return NullArgument("wl_shell_surface.set_popup", "seat")
+	}
+	if parent == nil {
+		return NullArgument("wl_shell_surface.set_popup", "parent")
+	}
+	msg := NewMessage(this.ObjectID, 6)
+	msg.PutObject(seat.ObjectID)
+	msg.PutUint(serial)
+	msg.PutObject(parent.ObjectID)
+	msg.PutInt(x)
+	msg.PutInt(y)
+	msg.PutUint(uint32(flags))
+	return this.Proxy.Send(msg)
 }
 
 // Map the surface as a maximized surface.
-// 
+//
 // If an output parameter is given then the surface will be
 // maximized on that output. If the client does not specify the
 // output then the compositor will apply its policy - usually
 // choosing the output on which the surface has the biggest surface
 // area.
-// 
+//
 // The compositor will reply with a configure event telling
 // the expected new surface size. The operation is completed
 // on the next buffer attach to this surface.
-// 
+//
 // A maximized surface typically fills the entire output it is
 // bound to, except for desktop elements such as panels. This is
 // the main difference between a maximized shell surface and a
 // fullscreen shell surface.
-// 
+//
 // The details depend on the compositor implementation.
-func (this *ShellSurface) SetMaximized(output uint32) error {
-    return nil
+func (this *ShellSurface) SetMaximized(output *Output) error {
+	msg := NewMessage(this.ObjectID, 7)
+	if output == nil {
+		msg.PutObject(0)
+	} else {
+		msg.PutObject(output.ObjectID)
+	}
+	return this.Proxy.Send(msg)
 }
 
 // Set a short title for the surface.
-// 
+//
 // This string may be used to identify the surface in a task bar,
 // window list, or other user interface elements provided by the
 // compositor.
-// 
+//
 // The string must be encoded in UTF-8.
 func (this *ShellSurface) SetTitle(title string) error {
-    return nil
+	msg := NewMessage(this.ObjectID, 8)
+	msg.PutString(title)
+	return this.Proxy.Send(msg)
 }
 
 // Set a class for the surface.
-// 
+//
 // The surface class identifies the general class of applications
 // to which the surface belongs. A common convention is to use the
 // file name (or the full path if it is a non-standard location) of
 // the application's .desktop file as the class.
 func (this *ShellSurface) SetClass(class string) error {
-    return nil
+	msg := NewMessage(this.ObjectID, 9)
+	msg.PutString(class)
+	return this.Proxy.Send(msg)
+}
+
+// These errors can be emitted in response to wl_surface requests.
+type SurfaceError uint32
+
+const SurfaceErrorInvalidScale SurfaceError = 0     // buffer scale value is invalid
+const SurfaceErrorInvalidTransform SurfaceError = 1 // buffer transform value is invalid
+
+func (e SurfaceError) String() string {
+	switch e {
+	case SurfaceErrorInvalidScale:
+		return "SurfaceErrorInvalidScale"
+	case SurfaceErrorInvalidTransform:
+		return "SurfaceErrorInvalidTransform"
+	}
+	return EnumString("SurfaceError", uint32(e))
+}
+
+const (
+	SurfaceDestroySinceVersion            = 1
+	SurfaceAttachSinceVersion             = 1
+	SurfaceDamageSinceVersion             = 1
+	SurfaceFrameSinceVersion              = 1
+	SurfaceSetOpaqueRegionSinceVersion    = 1
+	SurfaceSetInputRegionSinceVersion     = 1
+	SurfaceCommitSinceVersion             = 1
+	SurfaceSetBufferTransformSinceVersion = 2
+	SurfaceSetBufferScaleSinceVersion     = 3
+	SurfaceDamageBufferSinceVersion       = 4
+	SurfaceEnterSinceVersion              = 1
+	SurfaceLeaveSinceVersion              = 1
+)
+
+type SurfaceListener interface {
+	Enter(output *Output)
+	Leave(output *Output)
+}
+
+// SurfaceListenerFuncs implements SurfaceListener with a function per
+// event. Events whose function is nil are ignored.
+type SurfaceListenerFuncs struct {
+	OnEnter func(output *Output)
+	OnLeave func(output *Output)
+}
+
+func (this SurfaceListenerFuncs) Enter(output *Output) {
+	if this.OnEnter != nil {
+		this.OnEnter(output)
+	}
 }
 
+func (this SurfaceListenerFuncs) Leave(output *Output) {
+	if this.OnLeave != nil {
+		this.OnLeave(output)
+	}
+}
 
+// SurfaceEvent is an event of a wl_surface, one of the Surface*Event types.
+type SurfaceEvent interface {
+	isSurfaceEvent()
+}
 
+// SurfaceEnterEvent is the wl_surface.enter event.
+type SurfaceEnterEvent struct {
+	Output *Output
+}
 
-const SurfaceErrorInvalidScale = 0 // buffer scale value is invalid
-const SurfaceErrorInvalidTransform = 1 // buffer transform value is invalid
+func (SurfaceEnterEvent) isSurfaceEvent() {}
 
-type SurfaceListener interface {
-    Enter(output uint32)
-    Leave(output uint32)
+// SurfaceLeaveEvent is the wl_surface.leave event.
+type SurfaceLeaveEvent struct {
+	Output *Output
+}
+
+func (SurfaceLeaveEvent) isSurfaceEvent() {}
+
+var SurfaceInterface = &Interface{
+	Name:    "wl_surface",
+	Version: 4,
+	New:     func() Object { return &Surface{} },
+	Errors: map[uint32]string{
+		uint32(SurfaceErrorInvalidScale):     "SurfaceErrorInvalidScale",
+		uint32(SurfaceErrorInvalidTransform): "SurfaceErrorInvalidTransform",
+	},
 }
 
 // A surface is a rectangular area that is displayed on the screen.
 // It has a location, size and pixel contents.
-// 
+//
 // The size of a surface (and relative positions on it) is described
 // in surface-local coordinates, which may differ from the buffer
 // coordinates of the pixel content, in case a buffer_transform
 // or a buffer_scale is used.
-// 
+//
 // A surface without a "role" is fairly useless: a compositor does
 // not know where, when or how to present it. The role is the
 // purpose of a wl_surface. Examples of roles are a cursor for a
@@ -848,14 +2709,14 @@ type SurfaceListener interface {
 // (wl_data_device.start_drag), a sub-surface
 // (wl_subcompositor.get_subsurface), and a window as defined by a
 // shell protocol (e.g. wl_shell.get_shell_surface).
-// 
+//
 // A surface can have only one role at a time. Initially a
 // wl_surface does not have a role. Once a wl_surface is given a
 // role, it is set permanently for the whole lifetime of the
 // wl_surface object. Giving the current role again is allowed,
 // unless explicitly forbidden by the relevant interface
 // specification.
-// 
+//
 // Surface roles are given by requests in other interfaces such as
 // wl_pointer.set_cursor. The request should explicitly mention
 // that this request gives a role to a wl_surface. Often, this
@@ -863,7 +2724,7 @@ type SurfaceListener interface {
 // role and adds additional functionality to wl_surface. When a
 // client wants to destroy a wl_surface, they must destroy this 'role
 // object' before the wl_surface.
-// 
+//
 // Destroying the role object does not remove the role from the
 // wl_surface, but it may stop the wl_surface from "playing the role".
 // For instance, if a wl_subsurface object is destroyed, the wl_surface
@@ -873,41 +2734,97 @@ type SurfaceListener interface {
 // a cursor (cursor is a different role than sub-surface, and role
 // switching is not allowed).
 type Surface struct {
-    ObjectID
-    listener SurfaceListener
-}
-
-func (this *Surface) AddListener(listener SurfaceListener) {
-    this.listener = listener
+	Proxy
+}
+
+// AddListener adds a listener for the events of the wl_surface and returns
+// the function that removes it again.
+func (this *Surface) AddListener(listener SurfaceListener) func() {
+	return this.Proxy.AddListener(listener)
+}
+
+// Subscribe returns a channel receiving the events of the wl_surface as
+// they are dispatched, until ctx is done or the object is destroyed. See
+// the Subscribe function for how events are buffered.
+func (this *Surface) Subscribe(ctx context.Context) <-chan SurfaceEvent {
+	return Subscribe[SurfaceEvent](&this.Proxy, ctx)
+}
+
+// Events returns the channel receiving the events of the wl_surface until it
+// is destroyed. Every call returns the same channel, use Subscribe for
+// another receiver.
+func (this *Surface) Events() <-chan SurfaceEvent {
+	return Events[SurfaceEvent](&this.Proxy)
+}
+
+func (this *Surface) Interface() *Interface {
+	return SurfaceInterface
+}
+
+func (this *Surface) Decode(ev *Event) (func(), error) {
+	switch ev.Opcode {
+	case 0:
+		output, _ := ev.GetProxy(this.Proxy.Client()).(*Output)
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(SurfaceListener); ok {
+					listener.Enter(output)
+				}
+			}
+			this.Proxy.Publish(SurfaceEnterEvent{Output: output})
+		}, nil
+	case 1:
+		output, _ := ev.GetProxy(this.Proxy.Client()).(*Output)
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(SurfaceListener); ok {
+					listener.Leave(output)
+				}
+			}
+			this.Proxy.Publish(SurfaceLeaveEvent{Output: output})
+		}, nil
+	}
+	return nil, ev.InvalidOpcode()
+}
+
+func (this *Surface) EventFds(opcode uint16) int {
+	return 0
 }
 
 // Deletes the surface and invalidates its object ID.
 func (this *Surface) Destroy() error {
-    return nil
+	msg := NewMessage(this.ObjectID, 0)
+	return this.Proxy.SendDestructor(msg)
 }
 
 // Set a buffer as the content of this surface.
-// 
+//
 // The new size of the surface is calculated based on the buffer
 // size transformed by the inverse buffer_transform and the
 // inverse buffer_scale. This means that the supplied buffer
 // must be an integer multiple of the buffer_scale.
-// 
+//
 // The x and y arguments specify the location of the new pending
 // buffer's upper left corner, relative to the current buffer's upper
 // left corner, in surface-local coordinates. In other words, the
 // x and y, combined with the new surface size define in which
 // directions the surface's size changes.
-// 
+//
 // Surface contents are double-buffered state, see wl_surface.commit.
-// 
+//
 // The initial surface contents are void; there is no content.
 // wl_surface.attach assigns the given wl_buffer as the pending
 // wl_buffer. wl_surface.commit makes the pending wl_buffer the new
 // surface contents, and the size of the surface becomes the size
 // calculated from the wl_buffer, as described above. After commit,
 // there is no pending buffer until the next attach.
-// 
+//
 // Committing a pending wl_buffer allows the compositor to read the
 // pixels in the wl_buffer. The compositor may access the pixels at
 // any time after the wl_surface.commit request. When the compositor
@@ -917,131 +2834,162 @@ func (this *Surface) Destroy() error {
 // attached and then replaced by another attach instead of committed
 // will not receive a release event, and is not used by the
 // compositor.
-// 
+//
 // Destroying the wl_buffer after wl_buffer.release does not change
 // the surface contents. However, if the client destroys the
 // wl_buffer before receiving the wl_buffer.release event, the surface
 // contents become undefined immediately.
-// 
+//
 // If wl_surface.attach is sent with a NULL wl_buffer, the
 // following wl_surface.commit will remove the surface content.
-func (this *Surface) Attach(buffer uint32, x int32, y int32) error {
-    return nil
+func (this *Surface) Attach(buffer *Buffer, x int32, y int32) error {
+	msg := NewMessage(this.ObjectID, 1)
+	if buffer == nil {
+		msg.PutObject(0)
+	} else {
+		msg.PutObject(buffer.ObjectID)
+	}
+	msg.PutInt(x)
+	msg.PutInt(y)
+	return this.Proxy.Send(msg)
 }
 
 // This request is used to describe the regions where the pending
 // buffer is different from the current surface contents, and where
 // the surface therefore needs to be repainted. The compositor
 // ignores the parts of the damage that fall outside of the surface.
-// 
+//
 // Damage is double-buffered state, see wl_surface.commit.
-// 
+//
 // The damage rectangle is specified in surface-local coordinates,
 // where x and y specify the upper left corner of the damage rectangle.
-// 
+//
 // The initial value for pending damage is empty: no damage.
 // wl_surface.damage adds pending damage: the new pending damage
 // is the union of old pending damage and the given rectangle.
-// 
+//
 // wl_surface.commit assigns pending damage as the current damage,
 // and clears pending damage. The server will clear the current
 // damage as it repaints the surface.
-// 
+//
 // Alternatively, damage can be posted with wl_surface.damage_buffer
 // which uses buffer coordinates instead of surface coordinates,
 // and is probably the preferred and intuitive way of doing this.
 func (this *Surface) Damage(x int32, y int32, width int32, height int32) error {
-    return nil
+	msg := NewMessage(this.ObjectID, 2)
+	msg.PutInt(x)
+	msg.PutInt(y)
+	msg.PutInt(width)
+	msg.PutInt(height)
+	return this.Proxy.Send(msg)
 }
 
 // Request a notification when it is a good time to start drawing a new
 // frame, by creating a frame callback. This is useful for throttling
 // redrawing operations, and driving animations.
-// 
+//
 // When a client is animating on a wl_surface, it can use the 'frame'
 // request to get notified when it is a good time to draw and commit the
 // next frame of animation. If the client commits an update earlier than
 // that, it is likely that some updates will not make it to the display,
 // and the client is wasting resources by drawing too often.
-// 
+//
 // The frame request will take effect on the next wl_surface.commit.
 // The notification will only be posted for one frame unless
 // requested again. For a wl_surface, the notifications are posted in
 // the order the frame requests were committed.
-// 
+//
 // The server must send the notifications so that a client
 // will not send excessive updates, while still allowing
 // the highest possible update rate for clients that wait for the reply
 // before drawing again. The server should give some time for the client
 // to draw and commit after sending the frame callback events to let it
 // hit the next output refresh.
-// 
+//
 // A server should avoid signaling the frame callbacks if the
 // surface is not visible in any way, e.g. the surface is off-screen,
 // or completely obscured by other opaque surfaces.
-// 
+//
 // The object returned by this request will be destroyed by the
 // compositor after the callback is fired and as such the client must not
 // attempt to use it after that point.
-// 
+//
 // The callback_data passed in the callback is the current time, in
 // milliseconds, with an undefined base.
 func (this *Surface) Frame() (*Callback, error) {
-    return nil, nil
+	ret := &Callback{}
+	msg := NewMessage(this.ObjectID, 3)
+	msg.PutNewID(ret, this.Proxy.Version())
+	if err := this.Proxy.Send(msg); err != nil {
+		return nil, err
+	}
+	return ret, nil
 }
 
 // This request sets the region of the surface that contains
 // opaque content.
-// 
+//
 // The opaque region is an optimization hint for the compositor
 // that lets it optimize the redrawing of content behind opaque
 // regions.  Setting an opaque region is not required for correct
 // behaviour, but marking transparent content as opaque will result
 // in repaint artifacts.
-// 
+//
 // The opaque region is specified in surface-local coordinates.
-// 
+//
 // The compositor ignores the parts of the opaque region that fall
 // outside of the surface.
-// 
+//
 // Opaque region is double-buffered state, see wl_surface.commit.
-// 
+//
 // wl_surface.set_opaque_region changes the pending opaque region.
 // wl_surface.commit copies the pending region to the current region.
 // Otherwise, the pending and current regions are never changed.
-// 
+//
 // The initial value for an opaque region is empty. Setting the pending
 // opaque region has copy semantics, and the wl_region object can be
 // destroyed immediately. A NULL wl_region causes the pending opaque
 // region to be set to empty.
-func (this *Surface) SetOpaqueRegion(region uint32) error {
-    return nil
+func (this *Surface) SetOpaqueRegion(region *Region) error {
+	msg := NewMessage(this.ObjectID, 4)
+	if region == nil {
+		msg.PutObject(0)
+	} else {
+		msg.PutObject(region.ObjectID)
+	}
+	return this.Proxy.Send(msg)
 }
 
 // This request sets the region of the surface that can receive
 // pointer and touch events.
-// 
+//
 // Input events happening outside of this region will try the next
 // surface in the server surface stack. The compositor ignores the
 // parts of the input region that fall outside of the surface.
-// 
+//
 // The input region is specified in surface-local coordinates.
-// 
+//
 // Input region is double-buffered state, see wl_surface.commit.
-// 
+//
 // wl_surface.set_input_region changes the pending input region.
 // wl_surface.commit copies the pending region to the current region.
 // Otherwise the pending and current regions are never changed,
 // except cursor and icon surfaces are special cases, see
 // wl_pointer.set_cursor and wl_data_device.start_drag.
-// 
+//
 // The initial value for an input region is infinite. That means the
 // whole surface will accept input. Setting the pending input region
 // has copy semantics, and the wl_region object can be destroyed
 // immediately. A NULL wl_region causes the input region to be set
 // to infinite.
-func (this *Surface) SetInputRegion(region uint32) error {
-    return nil
+func (this *Surface) SetInputRegion(region *Region) error {
+	msg := NewMessage(this.ObjectID, 5)
+	if region == nil {
+		msg.PutObject(0)
+	} else {
+		msg.PutObject(region.ObjectID)
+	}
+	return this.Proxy.Send(msg)
 }
 
 // Surface state (input, opaque, and damage regions, attached buffers,
@@ -1050,35 +2998,36 @@ func (this *Surface) SetInputRegion(region uint32) error {
 // request atomically applies all pending state, replacing the current
 // state. After commit, the new pending state is as documented for each
 // related request.
-// 
+//
 // On commit, a pending wl_buffer is applied first, and all other state
 // second. This means that all coordinates in double-buffered state are
 // relative to the new wl_buffer coming into use, except for
 // wl_surface.attach itself. If there is no pending wl_buffer, the
 // coordinates are relative to the current surface contents.
-// 
+//
 // All requests that need a commit to become effective are documented
 // to affect double-buffered state.
-// 
+//
 // Other interfaces may add further double-buffered surface state.
 func (this *Surface) Commit() error {
-    return nil
+	msg := NewMessage(this.ObjectID, 6)
+	return this.Proxy.Send(msg)
 }
 
 // This request sets an optional transformation on how the compositor
 // interprets the contents of the buffer attached to the surface. The
 // accepted values for the transform parameter are the values for
 // wl_output.transform.
-// 
+//
 // Buffer transform is double-buffered state, see wl_surface.commit.
-// 
+//
 // A newly created surface has its buffer transformation set to normal.
-// 
+//
 // wl_surface.set_buffer_transform changes the pending buffer
 // transformation. wl_surface.commit copies the pending buffer
 // transformation to the current one. Otherwise, the pending and current
 // values are never changed.
-// 
+//
 // The purpose of this request is to allow clients to render content
 // according to the output transform, thus permitting the compositor to
 // use certain optimizations even if the display is rotated. Using
@@ -1086,70 +3035,80 @@ func (this *Surface) Commit() error {
 // surfaces are examples of such optimizations. Those optimizations are
 // highly dependent on the compositor implementation, so the use of this
 // request should be considered on a case-by-case basis.
-// 
+//
 // Note that if the transform value includes 90 or 270 degree rotation,
 // the width of the buffer will become the surface height and the height
 // of the buffer will become the surface width.
-// 
+//
 // If transform is not one of the values from the
 // wl_output.transform enum the invalid_transform protocol error
 // is raised.
-func (this *Surface) SetBufferTransform(transform int32) error {
-    return nil
+func (this *Surface) SetBufferTransform(transform OutputTransform) error {
+	if this.Proxy.Version() < SurfaceSetBufferTransformSinceVersion {
+		return UnsupportedVersion("wl_surface.set_buffer_transform", SurfaceSetBufferTransformSinceVersion, this.Proxy.Version())
+	}
+	msg := NewMessage(this.ObjectID, 7)
+	msg.PutUint(uint32(transform))
+	return this.Proxy.Send(msg)
 }
 
 // This request sets an optional scaling factor on how the compositor
 // interprets the contents of the buffer attached to the window.
-// 
+//
 // Buffer scale is double-buffered state, see wl_surface.commit.
-// 
+//
 // A newly created surface has its buffer scale set to 1.
-// 
+//
 // wl_surface.set_buffer_scale changes the pending buffer scale.
 // wl_surface.commit copies the pending buffer scale to the current one.
 // Otherwise, the pending and current values are never changed.
-// 
+//
 // The purpose of this request is to allow clients to supply higher
 // resolution buffer data for use on high resolution outputs. It is
 // intended that you pick the same buffer scale as the scale of the
 // output that the surface is displayed on. This means the compositor
 // can avoid scaling when rendering the surface on that output.
-// 
+//
 // Note that if the scale is larger than 1, then you have to attach
 // a buffer that is larger (by a factor of scale in each dimension)
 // than the desired surface size.
-// 
+//
 // If scale is not positive the invalid_scale protocol error is
 // raised.
 func (this *Surface) SetBufferScale(scale int32) error {
-    return nil
+	if this.Proxy.Version() < SurfaceSetBufferScaleSinceVersion {
+		return UnsupportedVersion("wl_surface.set_buffer_scale", SurfaceSetBufferScaleSinceVersion, this.Proxy.Version())
+	}
+	msg := NewMessage(this.ObjectID, 8)
+	msg.PutInt(scale)
+	return this.Proxy.Send(msg)
 }
 
 // This request is used to describe the regions where the pending
 // buffer is different from the current surface contents, and where
 // the surface therefore needs to be repainted. The compositor
 // ignores the parts of the damage that fall outside of the surface.
-// 
+//
 // Damage is double-buffered state, see wl_surface.commit.
-// 
+//
 // The damage rectangle is specified in buffer coordinates,
 // where x and y specify the upper left corner of the damage rectangle.
-// 
+//
 // The initial value for pending damage is empty: no damage.
 // wl_surface.damage_buffer adds pending damage: the new pending
 // damage is the union of old pending damage and the given rectangle.
-// 
+//
 // wl_surface.commit assigns pending damage as the current damage,
 // and clears pending damage. The server will clear the current
 // damage as it repaints the surface.
-// 
+//
 // This request differs from wl_surface.damage in only one way - it
 // takes damage in buffer coordinates instead of surface-local
 // coordinates. While this generally is more intuitive than surface
 // coordinates, it is especially desirable when using wp_viewport
 // or when a drawing library (like EGL) is unaware of buffer scale
 // and buffer transform.
-// 
+//
 // Note: Because buffer transformation changes and damage requests may
 // be interleaved in the protocol stream, it is impossible to determine
 // the actual mapping between surface and buffer damage until
@@ -1158,19 +3117,104 @@ func (this *Surface) SetBufferScale(scale int32) error {
 // two requests separately and only transform from one to the other
 // after receiving the wl_surface.commit.
 func (this *Surface) DamageBuffer(x int32, y int32, width int32, height int32) error {
-    return nil
+	if this.Proxy.Version() < SurfaceDamageBufferSinceVersion {
+		return UnsupportedVersion("wl_surface.damage_buffer", SurfaceDamageBufferSinceVersion, this.Proxy.Version())
+	}
+	msg := NewMessage(this.ObjectID, 9)
+	msg.PutInt(x)
+	msg.PutInt(y)
+	msg.PutInt(width)
+	msg.PutInt(height)
+	return this.Proxy.Send(msg)
+}
+
+// This is a bitmask of capabilities this seat has; if a member is
+// set, then it is present on the seat.
+type SeatCapability uint32
+
+const SeatCapabilityPointer SeatCapability = 1  // the seat has pointer devices
+const SeatCapabilityKeyboard SeatCapability = 2 // the seat has one or more keyboards
+const SeatCapabilityTouch SeatCapability = 4    // the seat has touch devices
+
+func (e SeatCapability) String() string {
+	return BitfieldString(uint32(e), []EnumEntry{
+		{uint32(SeatCapabilityPointer), "SeatCapabilityPointer"},
+		{uint32(SeatCapabilityKeyboard), "SeatCapabilityKeyboard"},
+		{uint32(SeatCapabilityTouch), "SeatCapabilityTouch"},
+	})
 }
 
+// Has reports whether every flag set in flag is also set in e.
+func (e SeatCapability) Has(flag SeatCapability) bool {
+	return e&flag == flag
+}
 
+// Set returns e with the flags in flag set.
+func (e SeatCapability) Set(flag SeatCapability) SeatCapability {
+	return e | flag
+}
 
+// Clear returns e with the flags in flag cleared.
+func (e SeatCapability) Clear(flag SeatCapability) SeatCapability {
+	return e &^ flag
+}
 
-const SeatCapabilityPointer = 1 // the seat has pointer devices
-const SeatCapabilityKeyboard = 2 // the seat has one or more keyboards
-const SeatCapabilityTouch = 4 // the seat has touch devices
+const (
+	SeatGetPointerSinceVersion   = 1
+	SeatGetKeyboardSinceVersion  = 1
+	SeatGetTouchSinceVersion     = 1
+	SeatReleaseSinceVersion      = 5
+	SeatCapabilitiesSinceVersion = 1
+	SeatNameSinceVersion         = 2
+)
 
 type SeatListener interface {
-    Capabilities(capabilities uint32)
-    Name(name string)
+	Capabilities(capabilities SeatCapability)
+	Name(name string)
+}
+
+// SeatListenerFuncs implements SeatListener with a function per
+// event. Events whose function is nil are ignored.
+type SeatListenerFuncs struct {
+	OnCapabilities func(capabilities SeatCapability)
+	OnName         func(name string)
+}
+
+func (this SeatListenerFuncs) Capabilities(capabilities SeatCapability) {
+	if this.OnCapabilities != nil {
+		this.OnCapabilities(capabilities)
+	}
+}
+
+func (this SeatListenerFuncs) Name(name string) {
+	if this.OnName != nil {
+		this.OnName(name)
+	}
+}
+
+// SeatEvent is an event of a wl_seat, one of the Seat*Event types.
+type SeatEvent interface {
+	isSeatEvent()
+}
+
+// SeatCapabilitiesEvent is the wl_seat.capabilities event.
+type SeatCapabilitiesEvent struct {
+	Capabilities SeatCapability
+}
+
+func (SeatCapabilitiesEvent) isSeatEvent() {}
+
+// SeatNameEvent is the wl_seat.name event.
+type SeatNameEvent struct {
+	Name string
+}
+
+func (SeatNameEvent) isSeatEvent() {}
+
+var SeatInterface = &Interface{
+	Name:    "wl_seat",
+	Version: 6,
+	New:     func() Object { return &Seat{} },
 }
 
 // A seat is a group of keyboards, pointer and touch devices. This
@@ -1178,241 +3222,1424 @@ type SeatListener interface {
 // device is hot plugged.  A seat typically has a pointer and
 // maintains a keyboard focus and a pointer focus.
 type Seat struct {
-    ObjectID
-    listener SeatListener
-}
-
-func (this *Seat) AddListener(listener SeatListener) {
-    this.listener = listener
+	Proxy
+}
+
+// AddListener adds a listener for the events of the wl_seat and returns
+// the function that removes it again.
+func (this *Seat) AddListener(listener SeatListener) func() {
+	return this.Proxy.AddListener(listener)
+}
+
+// Subscribe returns a channel receiving the events of the wl_seat as
+// they are dispatched, until ctx is done or the object is destroyed. See
+// the Subscribe function for how events are buffered.
+func (this *Seat) Subscribe(ctx context.Context) <-chan SeatEvent {
+	return Subscribe[SeatEvent](&this.Proxy, ctx)
+}
+
+// Events returns the channel receiving the events of the wl_seat until it
+// is destroyed. Every call returns the same channel, use Subscribe for
+// another receiver.
+func (this *Seat) Events() <-chan SeatEvent {
+	return Events[SeatEvent](&this.Proxy)
+}
+
+func (this *Seat) Interface() *Interface {
+	return SeatInterface
+}
+
+func (this *Seat) Decode(ev *Event) (func(), error) {
+	switch ev.Opcode {
+	case 0:
+		capabilities := SeatCapability(ev.GetUint())
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(SeatListener); ok {
+					listener.Capabilities(capabilities)
+				}
+			}
+			this.Proxy.Publish(SeatCapabilitiesEvent{Capabilities: capabilities})
+		}, nil
+	case 1:
+		name := ev.GetString()
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(SeatListener); ok {
+					listener.Name(name)
+				}
+			}
+			this.Proxy.Publish(SeatNameEvent{Name: name})
+		}, nil
+	}
+	return nil, ev.InvalidOpcode()
+}
+
+func (this *Seat) EventFds(opcode uint16) int {
+	return 0
 }
 
 // The ID provided will be initialized to the wl_pointer interface
 // for this seat.
-// 
+//
 // This request only takes effect if the seat has the pointer
 // capability, or has had the pointer capability in the past.
 // It is a protocol violation to issue this request on a seat that has
 // never had the pointer capability.
 func (this *Seat) GetPointer() (*Pointer, error) {
-    return nil, nil
+	ret := &Pointer{}
+	msg := NewMessage(this.ObjectID, 0)
+	msg.PutNewID(ret, this.Proxy.Version())
+	if err := this.Proxy.Send(msg); err != nil {
+		return nil, err
+	}
+	return ret, nil
 }
 
 // The ID provided will be initialized to the wl_keyboard interface
 // for this seat.
-// 
+//
 // This request only takes effect if the seat has the keyboard
 // capability, or has had the keyboard capability in the past.
 // It is a protocol violation to issue this request on a seat that has
 // never had the keyboard capability.
 func (this *Seat) GetKeyboard() (*Keyboard, error) {
-    return nil, nil
+	ret := &Keyboard{}
+	msg := NewMessage(this.ObjectID, 1)
+	msg.PutNewID(ret, this.Proxy.Version())
+	if err := this.Proxy.Send(msg); err != nil {
+		return nil, err
+	}
+	return ret, nil
 }
 
 // The ID provided will be initialized to the wl_touch interface
 // for this seat.
-// 
+//
 // This request only takes effect if the seat has the touch
 // capability, or has had the touch capability in the past.
 // It is a protocol violation to issue this request on a seat that has
 // never had the touch capability.
 func (this *Seat) GetTouch() (*Touch, error) {
-    return nil, nil
+	ret := &Touch{}
+	msg := NewMessage(this.ObjectID, 2)
+	msg.PutNewID(ret, this.Proxy.Version())
+	if err := this.Proxy.Send(msg); err != nil {
+		return nil, err
+	}
+	return ret, nil
 }
 
 // Using this request a client can tell the server that it is not going to
 // use the seat object anymore.
 func (this *Seat) Release() error {
-    return nil
+	if this.Proxy.Version() < SeatReleaseSinceVersion {
+		return UnsupportedVersion("wl_seat.release", SeatReleaseSinceVersion, this.Proxy.Version())
+	}
+	msg := NewMessage(this.ObjectID, 3)
+	return this.Proxy.SendDestructor(msg)
+}
+
+type PointerError uint32
+
+const PointerErrorRole PointerError = 0 // given wl_surface has another role
+
+func (e PointerError) String() string {
+	switch e {
+	case PointerErrorRole:
+		return "PointerErrorRole"
+	}
+	return EnumString("PointerError", uint32(e))
+}
+
+// Describes the physical state of a button that produced the button
+// event.
+type PointerButtonState uint32
+
+const PointerButtonStateReleased PointerButtonState = 0 // the button is not pressed
+const PointerButtonStatePressed PointerButtonState = 1  // the button is pressed
+
+func (e PointerButtonState) String() string {
+	switch e {
+	case PointerButtonStateReleased:
+		return "PointerButtonStateReleased"
+	case PointerButtonStatePressed:
+		return "PointerButtonStatePressed"
+	}
+	return EnumString("PointerButtonState", uint32(e))
+}
+
+// Describes the axis types of scroll events.
+type PointerAxis uint32
+
+const PointerAxisVerticalScroll PointerAxis = 0   // vertical axis
+const PointerAxisHorizontalScroll PointerAxis = 1 // horizontal axis
+
+func (e PointerAxis) String() string {
+	switch e {
+	case PointerAxisVerticalScroll:
+		return "PointerAxisVerticalScroll"
+	case PointerAxisHorizontalScroll:
+		return "PointerAxisHorizontalScroll"
+	}
+	return EnumString("PointerAxis", uint32(e))
+}
+
+// Describes the source types for axis events. This indicates to the
+// client how an axis event was physically generated; a client may
+// adjust the user interface accordingly. For example, scroll events
+// from a "finger" source may be in a smooth coordinate space with
+// kinetic scrolling whereas a "wheel" source may be in discrete steps
+// of a number of lines.
+//
+// The "continuous" axis source is a device generating events in a
+// continuous coordinate space, but using something other than a
+// finger. One example for this source is button-based scrolling where
+// the vertical motion of a device is converted to scroll events while
+// a button is held down.
+//
+// The "wheel tilt" axis source indicates that the actual device is a
+// wheel but the scroll event is not caused by a rotation but a
+// (usually sideways) tilt of the wheel.
+type PointerAxisSource uint32
+
+const PointerAxisSourceWheel PointerAxisSource = 0      // a physical wheel rotation
+const PointerAxisSourceFinger PointerAxisSource = 1     // finger on a touch surface
+const PointerAxisSourceContinuous PointerAxisSource = 2 // continuous coordinate space
+const PointerAxisSourceWheelTilt PointerAxisSource = 3  // a physical wheel tilt
+const PointerAxisSourceWheelTiltSinceVersion = 6
+
+func (e PointerAxisSource) String() string {
+	switch e {
+	case PointerAxisSourceWheel:
+		return "PointerAxisSourceWheel"
+	case PointerAxisSourceFinger:
+		return "PointerAxisSourceFinger"
+	case PointerAxisSourceContinuous:
+		return "PointerAxisSourceContinuous"
+	case PointerAxisSourceWheelTilt:
+		return "PointerAxisSourceWheelTilt"
+	}
+	return EnumString("PointerAxisSource", uint32(e))
+}
+
+const (
+	PointerSetCursorSinceVersion    = 1
+	PointerReleaseSinceVersion      = 3
+	PointerEnterSinceVersion        = 1
+	PointerLeaveSinceVersion        = 1
+	PointerMotionSinceVersion       = 1
+	PointerButtonSinceVersion       = 1
+	PointerAxisSinceVersion         = 1
+	PointerFrameSinceVersion        = 5
+	PointerAxisSourceSinceVersion   = 5
+	PointerAxisStopSinceVersion     = 5
+	PointerAxisDiscreteSinceVersion = 5
+)
+
+type PointerListener interface {
+	Enter(serial uint32, surface *Surface, surfaceX Fixed, surfaceY Fixed)
+	Leave(serial uint32, surface *Surface)
+	Motion(time uint32, surfaceX Fixed, surfaceY Fixed)
+	Button(serial uint32, time uint32, button uint32, state PointerButtonState)
+	Axis(time uint32, axis PointerAxis, value Fixed)
+	Frame()
+	AxisSource(axisSource PointerAxisSource)
+	AxisStop(time uint32, axis PointerAxis)
+	AxisDiscrete(axis PointerAxis, discrete int32)
 }
 
+// PointerListenerFuncs implements PointerListener with a function per
+// event. Events whose function is nil are ignored.
+type PointerListenerFuncs struct {
+	OnEnter        func(serial uint32, surface *Surface, surfaceX Fixed, surfaceY Fixed)
+	OnLeave        func(serial uint32, surface *Surface)
+	OnMotion       func(time uint32, surfaceX Fixed, surfaceY Fixed)
+	OnButton       func(serial uint32, time uint32, button uint32, state PointerButtonState)
+	OnAxis         func(time uint32, axis PointerAxis, value Fixed)
+	OnFrame        func()
+	OnAxisSource   func(axisSource PointerAxisSource)
+	OnAxisStop     func(time uint32, axis PointerAxis)
+	OnAxisDiscrete func(axis PointerAxis, discrete int32)
+}
 
+func (this PointerListenerFuncs) Enter(serial uint32, surface *Surface, surfaceX Fixed, surfaceY Fixed) {
+	if this.OnEnter != nil {
+		this.OnEnter(serial, surface, surfaceX, surfaceY)
+	}
+}
 
+func (this PointerListenerFuncs) Leave(serial uint32, surface *Surface) {
+	if this.OnLeave != nil {
+		this.OnLeave(serial, surface)
+	}
+}
 
-const PointerErrorRole = 0 // given wl_surface has another role
+func (this PointerListenerFuncs) Motion(time uint32, surfaceX Fixed, surfaceY Fixed) {
+	if this.OnMotion != nil {
+		this.OnMotion(time, surfaceX, surfaceY)
+	}
+}
 
+func (this PointerListenerFuncs) Button(serial uint32, time uint32, button uint32, state PointerButtonState) {
+	if this.OnButton != nil {
+		this.OnButton(serial, time, button, state)
+	}
+}
 
-const PointerButtonStateReleased = 0 // the button is not pressed
-const PointerButtonStatePressed = 1 // the button is pressed
+func (this PointerListenerFuncs) Axis(time uint32, axis PointerAxis, value Fixed) {
+	if this.OnAxis != nil {
+		this.OnAxis(time, axis, value)
+	}
+}
 
+func (this PointerListenerFuncs) Frame() {
+	if this.OnFrame != nil {
+		this.OnFrame()
+	}
+}
 
-const PointerAxisVerticalScroll = 0 // vertical axis
-const PointerAxisHorizontalScroll = 1 // horizontal axis
+func (this PointerListenerFuncs) AxisSource(axisSource PointerAxisSource) {
+	if this.OnAxisSource != nil {
+		this.OnAxisSource(axisSource)
+	}
+}
 
+func (this PointerListenerFuncs) AxisStop(time uint32, axis PointerAxis) {
+	if this.OnAxisStop != nil {
+		this.OnAxisStop(time, axis)
+	}
+}
 
-const PointerAxisSourceWheel = 0 // a physical wheel rotation
-const PointerAxisSourceFinger = 1 // finger on a touch surface
-const PointerAxisSourceContinuous = 2 // continuous coordinate space
-const PointerAxisSourceWheelTilt = 3 // a physical wheel tilt
+func (this PointerListenerFuncs) AxisDiscrete(axis PointerAxis, discrete int32) {
+	if this.OnAxisDiscrete != nil {
+		this.OnAxisDiscrete(axis, discrete)
+	}
+}
 
-type PointerListener interface {
-    Enter(serial uint32, surface uint32, surfaceX uint32, surfaceY uint32)
-    Leave(serial uint32, surface uint32)
-    Motion(time uint32, surfaceX uint32, surfaceY uint32)
-    Button(serial uint32, time uint32, button uint32, state uint32)
-    Axis(time uint32, axis uint32, value uint32)
-    Frame()
-    AxisSource(axisSource uint32)
-    AxisStop(time uint32, axis uint32)
-    AxisDiscrete(axis uint32, discrete int32)
+// PointerEvent is an event of a wl_pointer, one of the Pointer*Event types.
+type PointerEvent interface {
+	isPointerEvent()
+}
+
+// PointerEnterEvent is the wl_pointer.enter event.
+type PointerEnterEvent struct {
+	Serial   uint32
+	Surface  *Surface
+	SurfaceX Fixed
+	SurfaceY Fixed
+}
+
+func (PointerEnterEvent) isPointerEvent() {}
+
+// PointerLeaveEvent is the wl_pointer.leave event.
+type PointerLeaveEvent struct {
+	Serial  uint32
+	Surface *Surface
+}
+
+func (PointerLeaveEvent) isPointerEvent() {}
+
+// PointerMotionEvent is the wl_pointer.motion event.
+type PointerMotionEvent struct {
+	Time     uint32
+	SurfaceX Fixed
+	SurfaceY Fixed
+}
+
+func (PointerMotionEvent) isPointerEvent() {}
+
+// PointerButtonEvent is the wl_pointer.button event.
+type PointerButtonEvent struct {
+	Serial uint32
+	Time   uint32
+	Button uint32
+	State  PointerButtonState
+}
+
+func (PointerButtonEvent) isPointerEvent() {}
+
+// PointerAxisEvent is the wl_pointer.axis event.
+type PointerAxisEvent struct {
+	Time  uint32
+	Axis  PointerAxis
+	Value Fixed
+}
+
+func (PointerAxisEvent) isPointerEvent() {}
+
+// PointerFrameEvent is the wl_pointer.frame event.
+type PointerFrameEvent struct {
+}
+
+func (PointerFrameEvent) isPointerEvent() {}
+
+// PointerAxisSourceEvent is the wl_pointer.axis_source event.
+type PointerAxisSourceEvent struct {
+	AxisSource PointerAxisSource
+}
+
+func (PointerAxisSourceEvent) isPointerEvent() {}
+
+// PointerAxisStopEvent is the wl_pointer.axis_stop event.
+type PointerAxisStopEvent struct {
+	Time uint32
+	Axis PointerAxis
+}
+
+func (PointerAxisStopEvent) isPointerEvent() {}
+
+// PointerAxisDiscreteEvent is the wl_pointer.axis_discrete event.
+type PointerAxisDiscreteEvent struct {
+	Axis     PointerAxis
+	Discrete int32
+}
+
+func (PointerAxisDiscreteEvent) isPointerEvent() {}
+
+var PointerInterface = &Interface{
+	Name:    "wl_pointer",
+	Version: 6,
+	New:     func() Object { return &Pointer{} },
+	Errors: map[uint32]string{
+		uint32(PointerErrorRole): "PointerErrorRole",
+	},
 }
 
 // The wl_pointer interface represents one or more input devices,
 // such as mice, which control the pointer location and pointer_focus
 // of a seat.
-// 
+//
 // The wl_pointer interface generates motion, enter and leave
 // events for the surfaces that the pointer is located over,
 // and button and axis events for button presses, button releases
 // and scrolling.
 type Pointer struct {
-    ObjectID
-    listener PointerListener
-}
-
-func (this *Pointer) AddListener(listener PointerListener) {
-    this.listener = listener
+	Proxy
+}
+
+// AddListener adds a listener for the events of the wl_pointer and returns
+// the function that removes it again.
+func (this *Pointer) AddListener(listener PointerListener) func() {
+	return this.Proxy.AddListener(listener)
+}
+
+// Subscribe returns a channel receiving the events of the wl_pointer as
+// they are dispatched, until ctx is done or the object is destroyed. See
+// the Subscribe function for how events are buffered.
+func (this *Pointer) Subscribe(ctx context.Context) <-chan PointerEvent {
+	return Subscribe[PointerEvent](&this.Proxy, ctx)
+}
+
+// Events returns the channel receiving the events of the wl_pointer until it
+// is destroyed. Every call returns the same channel, use Subscribe for
+// another receiver.
+func (this *Pointer) Events() <-chan PointerEvent {
+	return Events[PointerEvent](&this.Proxy)
+}
+
+func (this *Pointer) Interface() *Interface {
+	return PointerInterface
+}
+
+func (this *Pointer) Decode(ev *Event) (func(), error) {
+	switch ev.Opcode {
+	case 0:
+		serial := ev.GetUint()
+		surface, _ := ev.GetProxy(this.Proxy.Client()).(*Surface)
+		surfaceX := ev.GetFixed()
+		surfaceY := ev.GetFixed()
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(PointerListener); ok {
+					listener.Enter(serial, surface, surfaceX, surfaceY)
+				}
+			}
+			this.Proxy.Publish(PointerEnterEvent{Serial: serial, Surface: surface, SurfaceX: surfaceX, SurfaceY: surfaceY})
+		}, nil
+	case 1:
+		serial := ev.GetUint()
+		surface, _ := ev.GetProxy(this.Proxy.Client()).(*Surface)
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(PointerListener); ok {
+					listener.Leave(serial, surface)
+				}
+			}
+			this.Proxy.Publish(PointerLeaveEvent{Serial: serial, Surface: surface})
+		}, nil
+	case 2:
+		time := ev.GetUint()
+		surfaceX := ev.GetFixed()
+		surfaceY := ev.GetFixed()
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(PointerListener); ok {
+					listener.Motion(time, surfaceX, surfaceY)
+				}
+			}
+			this.Proxy.Publish(PointerMotionEvent{Time: time, SurfaceX: surfaceX, SurfaceY: surfaceY})
+		}, nil
+	case 3:
+		serial := ev.GetUint()
+		time := ev.GetUint()
+		button := ev.GetUint()
+		state := PointerButtonState(ev.GetUint())
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(PointerListener); ok {
+					listener.Button(serial, time, button, state)
+				}
+			}
+			this.Proxy.Publish(PointerButtonEvent{Serial: serial, Time: time, Button: button, State: state})
+		}, nil
+	case 4:
+		time := ev.GetUint()
+		axis := PointerAxis(ev.GetUint())
+		value := ev.GetFixed()
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(PointerListener); ok {
+					listener.Axis(time, axis, value)
+				}
+			}
+			this.Proxy.Publish(PointerAxisEvent{Time: time, Axis: axis, Value: value})
+		}, nil
+	case 5:
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(PointerListener); ok {
+					listener.Frame()
+				}
+			}
+			this.Proxy.Publish(PointerFrameEvent{})
+		}, nil
+	case 6:
+		axisSource := PointerAxisSource(ev.GetUint())
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(PointerListener); ok {
+					listener.AxisSource(axisSource)
+				}
+			}
+			this.Proxy.Publish(PointerAxisSourceEvent{AxisSource: axisSource})
+		}, nil
+	case 7:
+		time := ev.GetUint()
+		axis := PointerAxis(ev.GetUint())
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(PointerListener); ok {
+					listener.AxisStop(time, axis)
+				}
+			}
+			this.Proxy.Publish(PointerAxisStopEvent{Time: time, Axis: axis})
+		}, nil
+	case 8:
+		axis := PointerAxis(ev.GetUint())
+		discrete := ev.GetInt()
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(PointerListener); ok {
+					listener.AxisDiscrete(axis, discrete)
+				}
+			}
+			this.Proxy.Publish(PointerAxisDiscreteEvent{Axis: axis, Discrete: discrete})
+		}, nil
+	}
+	return nil, ev.InvalidOpcode()
+}
+
+func (this *Pointer) EventFds(opcode uint16) int {
+	return 0
 }
 
 // Set the pointer surface, i.e., the surface that contains the
 // pointer image (cursor). This request gives the surface the role
 // of a cursor. If the surface already has another role, it raises
 // a protocol error.
-// 
+//
 // The cursor actually changes only if the pointer
 // focus for this device is one of the requesting client's surfaces
 // or the surface parameter is the current pointer surface. If
 // there was a previous surface set with this request it is
 // replaced. If surface is NULL, the pointer image is hidden.
-// 
+//
 // The parameters hotspot_x and hotspot_y define the position of
 // the pointer surface relative to the pointer location. Its
 // top-left corner is always at (x, y) - (hotspot_x, hotspot_y),
 // where (x, y) are the coordinates of the pointer location, in
 // surface-local coordinates.
-// 
+//
 // On surface.attach requests to the pointer surface, hotspot_x
 // and hotspot_y are decremented by the x and y parameters
 // passed to the request. Attach must be confirmed by
 // wl_surface.commit as usual.
-// 
+//
 // The hotspot can also be updated by passing the currently set
 // pointer surface to this request with new values for hotspot_x
 // and hotspot_y.
-// 
+//
 // The current and pending input regions of the wl_surface are
 // cleared, and wl_surface.set_input_region is ignored until the
 // wl_surface is no longer used as the cursor. When the use as a
 // cursor ends, the current and pending input regions become
 // undefined, and the wl_surface is unmapped.
-func (this *Pointer) SetCursor(serial uint32, surface uint32, hotspotX int32, hotspotY int32) error {
-    return nil
+func (this *Pointer) SetCursor(serial uint32, surface *Surface, hotspotX int32, hotspotY int32) error {
+	msg := NewMessage(this.ObjectID, 0)
+	msg.PutUint(serial)
+	if surface == nil {
+		msg.PutObject(0)
+	} else {
+		msg.PutObject(surface.ObjectID)
+	}
+	msg.PutInt(hotspotX)
+	msg.PutInt(hotspotY)
+	return this.Proxy.Send(msg)
 }
 
 // Using this request a client can tell the server that it is not going to
 // use the pointer object anymore.
-// 
+//
 // This request destroys the pointer proxy object, so clients must not call
 // wl_pointer_destroy() after using this request.
 func (this *Pointer) Release() error {
-    return nil
+	if this.Proxy.Version() < PointerReleaseSinceVersion {
+		return UnsupportedVersion("wl_pointer.release", PointerReleaseSinceVersion, this.Proxy.Version())
+	}
+	msg := NewMessage(this.ObjectID, 1)
+	return this.Proxy.SendDestructor(msg)
+}
+
+// This specifies the format of the keymap provided to the
+// client with the wl_keyboard.keymap event.
+type KeyboardKeymapFormat uint32
+
+const KeyboardKeymapFormatNoKeymap KeyboardKeymapFormat = 0 // no keymap; client must understand how to interpret the raw keycode
+const KeyboardKeymapFormatXkbV1 KeyboardKeymapFormat = 1    // libxkbcommon compatible; to determine the xkb keycode, clients must add 8 to the key event keycode
+
+func (e KeyboardKeymapFormat) String() string {
+	switch e {
+	case KeyboardKeymapFormatNoKeymap:
+		return "KeyboardKeymapFormatNoKeymap"
+	case KeyboardKeymapFormatXkbV1:
+		return "KeyboardKeymapFormatXkbV1"
+	}
+	return EnumString("KeyboardKeymapFormat", uint32(e))
+}
+
+// Describes the physical state of a key that produced the key event.
+type KeyboardKeyState uint32
+
+const KeyboardKeyStateReleased KeyboardKeyState = 0 // key is not pressed
+const KeyboardKeyStatePressed KeyboardKeyState = 1  // key is pressed
+
+func (e KeyboardKeyState) String() string {
+	switch e {
+	case KeyboardKeyStateReleased:
+		return "KeyboardKeyStateReleased"
+	case KeyboardKeyStatePressed:
+		return "KeyboardKeyStatePressed"
+	}
+	return EnumString("KeyboardKeyState", uint32(e))
+}
+
+const (
+	KeyboardReleaseSinceVersion    = 3
+	KeyboardKeymapSinceVersion     = 1
+	KeyboardEnterSinceVersion      = 1
+	KeyboardLeaveSinceVersion      = 1
+	KeyboardKeySinceVersion        = 1
+	KeyboardModifiersSinceVersion  = 1
+	KeyboardRepeatInfoSinceVersion = 4
+)
+
+type KeyboardListener interface {
+	Keymap(format KeyboardKeymapFormat, fd *os.File, size uint32)
+	Enter(serial uint32, surface *Surface, keys []byte)
+	Leave(serial uint32, surface *Surface)
+	Key(serial uint32, time uint32, key uint32, state KeyboardKeyState)
+	Modifiers(serial uint32, modsDepressed uint32, modsLatched uint32, modsLocked uint32, group uint32)
+	RepeatInfo(rate int32, delay int32)
 }
 
+// KeyboardListenerFuncs implements KeyboardListener with a function per
+// event. Events whose function is nil are ignored.
+type KeyboardListenerFuncs struct {
+	OnKeymap     func(format KeyboardKeymapFormat, fd *os.File, size uint32)
+	OnEnter      func(serial uint32, surface *Surface, keys []byte)
+	OnLeave      func(serial uint32, surface *Surface)
+	OnKey        func(serial uint32, time uint32, key uint32, state KeyboardKeyState)
+	OnModifiers  func(serial uint32, modsDepressed uint32, modsLatched uint32, modsLocked uint32, group uint32)
+	OnRepeatInfo func(rate int32, delay int32)
+}
+
+func (this KeyboardListenerFuncs) Keymap(format KeyboardKeymapFormat, fd *os.File, size uint32) {
+	if this.OnKeymap != nil {
+		this.OnKeymap(format, fd, size)
+	}
+}
 
+func (this KeyboardListenerFuncs) Enter(serial uint32, surface *Surface, keys []byte) {
+	if this.OnEnter != nil {
+		this.OnEnter(serial, surface, keys)
+	}
+}
 
+func (this KeyboardListenerFuncs) Leave(serial uint32, surface *Surface) {
+	if this.OnLeave != nil {
+		this.OnLeave(serial, surface)
+	}
+}
 
-const KeyboardKeymapFormatNoKeymap = 0 // no keymap; client must understand how to interpret the raw keycode
-const KeyboardKeymapFormatXkbV1 = 1 // libxkbcommon compatible; to determine the xkb keycode, clients must add 8 to the key event keycode
+func (this KeyboardListenerFuncs) Key(serial uint32, time uint32, key uint32, state KeyboardKeyState) {
+	if this.OnKey != nil {
+		this.OnKey(serial, time, key, state)
+	}
+}
 
+func (this KeyboardListenerFuncs) Modifiers(serial uint32, modsDepressed uint32, modsLatched uint32, modsLocked uint32, group uint32) {
+	if this.OnModifiers != nil {
+		this.OnModifiers(serial, modsDepressed, modsLatched, modsLocked, group)
+	}
+}
 
-const KeyboardKeyStateReleased = 0 // key is not pressed
-const KeyboardKeyStatePressed = 1 // key is pressed
+func (this KeyboardListenerFuncs) RepeatInfo(rate int32, delay int32) {
+	if this.OnRepeatInfo != nil {
+		this.OnRepeatInfo(rate, delay)
+	}
+}
 
-type KeyboardListener interface {
-    Keymap(format uint32, size uint32)
-    Enter(serial uint32, surface uint32, keys []byte)
-    Leave(serial uint32, surface uint32)
-    Key(serial uint32, time uint32, key uint32, state uint32)
-    Modifiers(serial uint32, modsDepressed uint32, modsLatched uint32, modsLocked uint32, group uint32)
-    RepeatInfo(rate int32, delay int32)
+// KeyboardEvent is an event of a wl_keyboard, one of the Keyboard*Event types.
+type KeyboardEvent interface {
+	isKeyboardEvent()
+}
+
+// KeyboardKeymapEvent is the wl_keyboard.keymap event.
+type KeyboardKeymapEvent struct {
+	Format KeyboardKeymapFormat
+	Fd     *os.File
+	Size   uint32
+}
+
+func (KeyboardKeymapEvent) isKeyboardEvent() {}
+
+// KeyboardEnterEvent is the wl_keyboard.enter event.
+type KeyboardEnterEvent struct {
+	Serial  uint32
+	Surface *Surface
+	Keys    []byte
+}
+
+func (KeyboardEnterEvent) isKeyboardEvent() {}
+
+// KeyboardLeaveEvent is the wl_keyboard.leave event.
+type KeyboardLeaveEvent struct {
+	Serial  uint32
+	Surface *Surface
+}
+
+func (KeyboardLeaveEvent) isKeyboardEvent() {}
+
+// KeyboardKeyEvent is the wl_keyboard.key event.
+type KeyboardKeyEvent struct {
+	Serial uint32
+	Time   uint32
+	Key    uint32
+	State  KeyboardKeyState
+}
+
+func (KeyboardKeyEvent) isKeyboardEvent() {}
+
+// KeyboardModifiersEvent is the wl_keyboard.modifiers event.
+type KeyboardModifiersEvent struct {
+	Serial        uint32
+	ModsDepressed uint32
+	ModsLatched   uint32
+	ModsLocked    uint32
+	Group         uint32
+}
+
+func (KeyboardModifiersEvent) isKeyboardEvent() {}
+
+// KeyboardRepeatInfoEvent is the wl_keyboard.repeat_info event.
+type KeyboardRepeatInfoEvent struct {
+	Rate  int32
+	Delay int32
+}
+
+func (KeyboardRepeatInfoEvent) isKeyboardEvent() {}
+
+var KeyboardInterface = &Interface{
+	Name:    "wl_keyboard",
+	Version: 6,
+	New:     func() Object { return &Keyboard{} },
 }
 
 // The wl_keyboard interface represents one or more keyboards
 // associated with a seat.
 type Keyboard struct {
-    ObjectID
-    listener KeyboardListener
+	Proxy
+}
+
+// AddListener adds a listener for the events of the wl_keyboard and returns
+// the function that removes it again.
+func (this *Keyboard) AddListener(listener KeyboardListener) func() {
+	return this.Proxy.AddListener(listener)
+}
+
+// Subscribe returns a channel receiving the events of the wl_keyboard as
+// they are dispatched, until ctx is done or the object is destroyed. See
+// the Subscribe function for how events are buffered.
+func (this *Keyboard) Subscribe(ctx context.Context) <-chan KeyboardEvent {
+	return Subscribe[KeyboardEvent](&this.Proxy, ctx)
+}
+
+// Events returns the channel receiving the events of the wl_keyboard until it
+// is destroyed. Every call returns the same channel, use Subscribe for
+// another receiver.
+func (this *Keyboard) Events() <-chan KeyboardEvent {
+	return Events[KeyboardEvent](&this.Proxy)
+}
+
+func (this *Keyboard) Interface() *Interface {
+	return KeyboardInterface
+}
+
+func (this *Keyboard) Decode(ev *Event) (func(), error) {
+	switch ev.Opcode {
+	case 0:
+		format := KeyboardKeymapFormat(ev.GetUint())
+		fd := ev.GetFd()
+		size := ev.GetUint()
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(KeyboardListener); ok {
+					listener.Keymap(format, fd, size)
+				}
+			}
+			this.Proxy.Publish(KeyboardKeymapEvent{Format: format, Fd: fd, Size: size})
+		}, nil
+	case 1:
+		serial := ev.GetUint()
+		surface, _ := ev.GetProxy(this.Proxy.Client()).(*Surface)
+		keys := ev.GetArray()
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(KeyboardListener); ok {
+					listener.Enter(serial, surface, keys)
+				}
+			}
+			this.Proxy.Publish(KeyboardEnterEvent{Serial: serial, Surface: surface, Keys: keys})
+		}, nil
+	case 2:
+		serial := ev.GetUint()
+		surface, _ := ev.GetProxy(this.Proxy.Client()).(*Surface)
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(KeyboardListener); ok {
+					listener.Leave(serial, surface)
+				}
+			}
+			this.Proxy.Publish(KeyboardLeaveEvent{Serial: serial, Surface: surface})
+		}, nil
+	case 3:
+		serial := ev.GetUint()
+		time := ev.GetUint()
+		key := ev.GetUint()
+		state := KeyboardKeyState(ev.GetUint())
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(KeyboardListener); ok {
+					listener.Key(serial, time, key, state)
+				}
+			}
+			this.Proxy.Publish(KeyboardKeyEvent{Serial: serial, Time: time, Key: key, State: state})
+		}, nil
+	case 4:
+		serial := ev.GetUint()
+		modsDepressed := ev.GetUint()
+		modsLatched := ev.GetUint()
+		modsLocked := ev.GetUint()
+		group := ev.GetUint()
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(KeyboardListener); ok {
+					listener.Modifiers(serial, modsDepressed, modsLatched, modsLocked, group)
+				}
+			}
+			this.Proxy.Publish(KeyboardModifiersEvent{Serial: serial, ModsDepressed: modsDepressed, ModsLatched: modsLatched, ModsLocked: modsLocked, Group: group})
+		}, nil
+	case 5:
+		rate := ev.GetInt()
+		delay := ev.GetInt()
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(KeyboardListener); ok {
+					listener.RepeatInfo(rate, delay)
+				}
+			}
+			this.Proxy.Publish(KeyboardRepeatInfoEvent{Rate: rate, Delay: delay})
+		}, nil
+	}
+	return nil, ev.InvalidOpcode()
+}
+
+func (this *Keyboard) EventFds(opcode uint16) int {
+	if opcode == 0 {
+		return 1
+	}
+	return 0
+}
+
+func (this *Keyboard) Release() error {
+	if this.Proxy.Version() < KeyboardReleaseSinceVersion {
+		return UnsupportedVersion("wl_keyboard.release", KeyboardReleaseSinceVersion, this.Proxy.Version())
+	}
+	msg := NewMessage(this.ObjectID, 0)
+	return this.Proxy.SendDestructor(msg)
+}
+
+const (
+	TouchReleaseSinceVersion     = 3
+	TouchDownSinceVersion        = 1
+	TouchUpSinceVersion          = 1
+	TouchMotionSinceVersion      = 1
+	TouchFrameSinceVersion       = 1
+	TouchCancelSinceVersion      = 1
+	TouchShapeSinceVersion       = 6
+	TouchOrientationSinceVersion = 6
+)
+
+type TouchListener interface {
+	Down(serial uint32, time uint32, surface *Surface, id int32, x Fixed, y Fixed)
+	Up(serial uint32, time uint32, id int32)
+	Motion(time uint32, id int32, x Fixed, y Fixed)
+	Frame()
+	Cancel()
+	Shape(id int32, major Fixed, minor Fixed)
+	Orientation(id int32, orientation Fixed)
 }
 
-func (this *Keyboard) AddListener(listener KeyboardListener) {
-    this.listener = listener
+// TouchListenerFuncs implements TouchListener with a function per
+// event. Events whose function is nil are ignored.
+type TouchListenerFuncs struct {
+	OnDown        func(serial uint32, time uint32, surface *Surface, id int32, x Fixed, y Fixed)
+	OnUp          func(serial uint32, time uint32, id int32)
+	OnMotion      func(time uint32, id int32, x Fixed, y Fixed)
+	OnFrame       func()
+	OnCancel      func()
+	OnShape       func(id int32, major Fixed, minor Fixed)
+	OnOrientation func(id int32, orientation Fixed)
 }
 
-func (this *Keyboard) Release() error {
-    return nil
+func (this TouchListenerFuncs) Down(serial uint32, time uint32, surface *Surface, id int32, x Fixed, y Fixed) {
+	if this.OnDown != nil {
+		this.OnDown(serial, time, surface, id, x, y)
+	}
 }
 
+func (this TouchListenerFuncs) Up(serial uint32, time uint32, id int32) {
+	if this.OnUp != nil {
+		this.OnUp(serial, time, id)
+	}
+}
 
+func (this TouchListenerFuncs) Motion(time uint32, id int32, x Fixed, y Fixed) {
+	if this.OnMotion != nil {
+		this.OnMotion(time, id, x, y)
+	}
+}
 
-type TouchListener interface {
-    Down(serial uint32, time uint32, surface uint32, id int32, x uint32, y uint32)
-    Up(serial uint32, time uint32, id int32)
-    Motion(time uint32, id int32, x uint32, y uint32)
-    Frame()
-    Cancel()
-    Shape(id int32, major uint32, minor uint32)
-    Orientation(id int32, orientation uint32)
+func (this TouchListenerFuncs) Frame() {
+	if this.OnFrame != nil {
+		this.OnFrame()
+	}
+}
+
+func (this TouchListenerFuncs) Cancel() {
+	if this.OnCancel != nil {
+		this.OnCancel()
+	}
+}
+
+func (this TouchListenerFuncs) Shape(id int32, major Fixed, minor Fixed) {
+	if this.OnShape != nil {
+		this.OnShape(id, major, minor)
+	}
+}
+
+func (this TouchListenerFuncs) Orientation(id int32, orientation Fixed) {
+	if this.OnOrientation != nil {
+		this.OnOrientation(id, orientation)
+	}
+}
+
+// TouchEvent is an event of a wl_touch, one of the Touch*Event types.
+type TouchEvent interface {
+	isTouchEvent()
+}
+
+// TouchDownEvent is the wl_touch.down event.
+type TouchDownEvent struct {
+	Serial  uint32
+	Time    uint32
+	Surface *Surface
+	ID      int32
+	X       Fixed
+	Y       Fixed
+}
+
+func (TouchDownEvent) isTouchEvent() {}
+
+// TouchUpEvent is the wl_touch.up event.
+type TouchUpEvent struct {
+	Serial uint32
+	Time   uint32
+	ID     int32
+}
+
+func (TouchUpEvent) isTouchEvent() {}
+
+// TouchMotionEvent is the wl_touch.motion event.
+type TouchMotionEvent struct {
+	Time uint32
+	ID   int32
+	X    Fixed
+	Y    Fixed
+}
+
+func (TouchMotionEvent) isTouchEvent() {}
+
+// TouchFrameEvent is the wl_touch.frame event.
+type TouchFrameEvent struct {
+}
+
+func (TouchFrameEvent) isTouchEvent() {}
+
+// TouchCancelEvent is the wl_touch.cancel event.
+type TouchCancelEvent struct {
+}
+
+func (TouchCancelEvent) isTouchEvent() {}
+
+// TouchShapeEvent is the wl_touch.shape event.
+type TouchShapeEvent struct {
+	ID    int32
+	Major Fixed
+	Minor Fixed
+}
+
+func (TouchShapeEvent) isTouchEvent() {}
+
+// TouchOrientationEvent is the wl_touch.orientation event.
+type TouchOrientationEvent struct {
+	ID          int32
+	Orientation Fixed
+}
+
+func (TouchOrientationEvent) isTouchEvent() {}
+
+var TouchInterface = &Interface{
+	Name:    "wl_touch",
+	Version: 6,
+	New:     func() Object { return &Touch{} },
 }
 
 // The wl_touch interface represents a touchscreen
 // associated with a seat.
-// 
+//
 // Touch interactions can consist of one or more contacts.
 // For each contact, a series of events is generated, starting
 // with a down event, followed by zero or more motion events,
 // and ending with an up event. Events relating to the same
 // contact point can be identified by the ID of the sequence.
 type Touch struct {
-    ObjectID
-    listener TouchListener
+	Proxy
+}
+
+// AddListener adds a listener for the events of the wl_touch and returns
+// the function that removes it again.
+func (this *Touch) AddListener(listener TouchListener) func() {
+	return this.Proxy.AddListener(listener)
+}
+
+// Subscribe returns a channel receiving the events of the wl_touch as
+// they are dispatched, until ctx is done or the object is destroyed. See
+// the Subscribe function for how events are buffered.
+func (this *Touch) Subscribe(ctx context.Context) <-chan TouchEvent {
+	return Subscribe[TouchEvent](&this.Proxy, ctx)
+}
+
+// Events returns the channel receiving the events of the wl_touch until it
+// is destroyed. Every call returns the same channel, use Subscribe for
+// another receiver.
+func (this *Touch) Events() <-chan TouchEvent {
+	return Events[TouchEvent](&this.Proxy)
+}
+
+func (this *Touch) Interface() *Interface {
+	return TouchInterface
+}
+
+func (this *Touch) Decode(ev *Event) (func(), error) {
+	switch ev.Opcode {
+	case 0:
+		serial := ev.GetUint()
+		time := ev.GetUint()
+		surface, _ := ev.GetProxy(this.Proxy.Client()).(*Surface)
+		id := ev.GetInt()
+		x := ev.GetFixed()
+		y := ev.GetFixed()
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(TouchListener); ok {
+					listener.Down(serial, time, surface, id, x, y)
+				}
+			}
+			this.Proxy.Publish(TouchDownEvent{Serial: serial, Time: time, Surface: surface, ID: id, X: x, Y: y})
+		}, nil
+	case 1:
+		serial := ev.GetUint()
+		time := ev.GetUint()
+		id := ev.GetInt()
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(TouchListener); ok {
+					listener.Up(serial, time, id)
+				}
+			}
+			this.Proxy.Publish(TouchUpEvent{Serial: serial, Time: time, ID: id})
+		}, nil
+	case 2:
+		time := ev.GetUint()
+		id := ev.GetInt()
+		x := ev.GetFixed()
+		y := ev.GetFixed()
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(TouchListener); ok {
+					listener.Motion(time, id, x, y)
+				}
+			}
+			this.Proxy.Publish(TouchMotionEvent{Time: time, ID: id, X: x, Y: y})
+		}, nil
+	case 3:
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(TouchListener); ok {
+					listener.Frame()
+				}
+			}
+			this.Proxy.Publish(TouchFrameEvent{})
+		}, nil
+	case 4:
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(TouchListener); ok {
+					listener.Cancel()
+				}
+			}
+			this.Proxy.Publish(TouchCancelEvent{})
+		}, nil
+	case 5:
+		id := ev.GetInt()
+		major := ev.GetFixed()
+		minor := ev.GetFixed()
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(TouchListener); ok {
+					listener.Shape(id, major, minor)
+				}
+			}
+			this.Proxy.Publish(TouchShapeEvent{ID: id, Major: major, Minor: minor})
+		}, nil
+	case 6:
+		id := ev.GetInt()
+		orientation := ev.GetFixed()
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(TouchListener); ok {
+					listener.Orientation(id, orientation)
+				}
+			}
+			this.Proxy.Publish(TouchOrientationEvent{ID: id, Orientation: orientation})
+		}, nil
+	}
+	return nil, ev.InvalidOpcode()
+}
+
+func (this *Touch) EventFds(opcode uint16) int {
+	return 0
 }
 
-func (this *Touch) AddListener(listener TouchListener) {
-    this.listener = listener
+func (this *Touch) Release() error {
+	if this.Proxy.Version() < TouchReleaseSinceVersion {
+		return UnsupportedVersion("wl_touch.release", TouchReleaseSinceVersion, this.Proxy.Version())
+	}
+	msg := NewMessage(this.ObjectID, 0)
+	return this.Proxy.SendDestructor(msg)
+}
+
+// This enumeration describes how the physical
+// pixels on an output are laid out.
+type OutputSubpixel uint32
+
+const OutputSubpixelUnknown OutputSubpixel = 0       // unknown geometry
+const OutputSubpixelNone OutputSubpixel = 1          // no geometry
+const OutputSubpixelHorizontalRgb OutputSubpixel = 2 // horizontal RGB
+const OutputSubpixelHorizontalBgr OutputSubpixel = 3 // horizontal BGR
+const OutputSubpixelVerticalRgb OutputSubpixel = 4   // vertical RGB
+const OutputSubpixelVerticalBgr OutputSubpixel = 5   // vertical BGR
+
+func (e OutputSubpixel) String() string {
+	switch e {
+	case OutputSubpixelUnknown:
+		return "OutputSubpixelUnknown"
+	case OutputSubpixelNone:
+		return "OutputSubpixelNone"
+	case OutputSubpixelHorizontalRgb:
+		return "OutputSubpixelHorizontalRgb"
+	case OutputSubpixelHorizontalBgr:
+		return "OutputSubpixelHorizontalBgr"
+	case OutputSubpixelVerticalRgb:
+		return "OutputSubpixelVerticalRgb"
+	case OutputSubpixelVerticalBgr:
+		return "OutputSubpixelVerticalBgr"
+	}
+	return EnumString("OutputSubpixel", uint32(e))
+}
+
+// This describes the transform that a compositor will apply to a
+// surface to compensate for the rotation or mirroring of an
+// output device.
+//
+// The flipped values correspond to an initial flip around a
+// vertical axis followed by rotation.
+//
+// The purpose is mainly to allow clients to render accordingly and
+// tell the compositor, so that for fullscreen surfaces, the
+// compositor will still be able to scan out directly from client
+// surfaces.
+type OutputTransform uint32
+
+const OutputTransformNormal OutputTransform = 0     // no transform
+const OutputTransform90 OutputTransform = 1         // 90 degrees counter-clockwise
+const OutputTransform180 OutputTransform = 2        // 180 degrees counter-clockwise
+const OutputTransform270 OutputTransform = 3        // 270 degrees counter-clockwise
+const OutputTransformFlipped OutputTransform = 4    // 180 degree flip around a vertical axis
+const OutputTransformFlipped90 OutputTransform = 5  // flip and rotate 90 degrees counter-clockwise
+const OutputTransformFlipped180 OutputTransform = 6 // flip and rotate 180 degrees counter-clockwise
+const OutputTransformFlipped270 OutputTransform = 7 // flip and rotate 270 degrees counter-clockwise
+
+func (e OutputTransform) String() string {
+	switch e {
+	case OutputTransformNormal:
+		return "OutputTransformNormal"
+	case OutputTransform90:
+		return "OutputTransform90"
+	case OutputTransform180:
+		return "OutputTransform180"
+	case OutputTransform270:
+		return "OutputTransform270"
+	case OutputTransformFlipped:
+		return "OutputTransformFlipped"
+	case OutputTransformFlipped90:
+		return "OutputTransformFlipped90"
+	case OutputTransformFlipped180:
+		return "OutputTransformFlipped180"
+	case OutputTransformFlipped270:
+		return "OutputTransformFlipped270"
+	}
+	return EnumString("OutputTransform", uint32(e))
+}
+
+// These flags describe properties of an output mode.
+// They are used in the flags bitfield of the mode event.
+type OutputMode uint32
+
+const OutputModeCurrent OutputMode = 0x1   // indicates this is the current mode
+const OutputModePreferred OutputMode = 0x2 // indicates this is the preferred mode
+
+func (e OutputMode) String() string {
+	return BitfieldString(uint32(e), []EnumEntry{
+		{uint32(OutputModeCurrent), "OutputModeCurrent"},
+		{uint32(OutputModePreferred), "OutputModePreferred"},
+	})
+}
+
+// Has reports whether every flag set in flag is also set in e.
+func (e OutputMode) Has(flag OutputMode) bool {
+	return e&flag == flag
+}
+
+// Set returns e with the flags in flag set.
+func (e OutputMode) Set(flag OutputMode) OutputMode {
+	return e | flag
+}
+
+// Clear returns e with the flags in flag cleared.
+func (e OutputMode) Clear(flag OutputMode) OutputMode {
+	return e &^ flag
+}
+
+const (
+	OutputReleaseSinceVersion  = 3
+	OutputGeometrySinceVersion = 1
+	OutputModeSinceVersion     = 1
+	OutputDoneSinceVersion     = 2
+	OutputScaleSinceVersion    = 2
+)
+
+type OutputListener interface {
+	Geometry(x int32, y int32, physicalWidth int32, physicalHeight int32, subpixel OutputSubpixel, make_ string, model string, transform OutputTransform)
+	Mode(flags OutputMode, width int32, height int32, refresh int32)
+	Done()
+	Scale(factor int32)
 }
 
-func (this *Touch) Release() error {
-    return nil
+// OutputListenerFuncs implements OutputListener with a function per
+// event. Events whose function is nil are ignored.
+type OutputListenerFuncs struct {
+	OnGeometry func(x int32, y int32, physicalWidth int32, physicalHeight int32, subpixel OutputSubpixel, make_ string, model string, transform OutputTransform)
+	OnMode     func(flags OutputMode, width int32, height int32, refresh int32)
+	OnDone     func()
+	OnScale    func(factor int32)
+}
+
+func (this OutputListenerFuncs) Geometry(x int32, y int32, physicalWidth int32, physicalHeight int32, subpixel OutputSubpixel, make_ string, model string, transform OutputTransform) {
+	if this.OnGeometry != nil {
+		this.OnGeometry(x, y, physicalWidth, physicalHeight, subpixel, make_, model, transform)
+	}
+}
+
+func (this OutputListenerFuncs) Mode(flags OutputMode, width int32, height int32, refresh int32) {
+	if this.OnMode != nil {
+		this.OnMode(flags, width, height, refresh)
+	}
+}
+
+func (this OutputListenerFuncs) Done() {
+	if this.OnDone != nil {
+		this.OnDone()
+	}
+}
+
+func (this OutputListenerFuncs) Scale(factor int32) {
+	if this.OnScale != nil {
+		this.OnScale(factor)
+	}
 }
 
+// OutputEvent is an event of a wl_output, one of the Output*Event types.
+type OutputEvent interface {
+	isOutputEvent()
+}
 
+// OutputGeometryEvent is the wl_output.geometry event.
+type OutputGeometryEvent struct {
+	X              int32
+	Y              int32
+	PhysicalWidth  int32
+	PhysicalHeight int32
+	Subpixel       OutputSubpixel
+	Make           string
+	Model          string
+	Transform      OutputTransform
+}
 
+func (OutputGeometryEvent) isOutputEvent() {}
 
-const OutputSubpixelUnknown = 0 // unknown geometry
-const OutputSubpixelNone = 1 // no geometry
-const OutputSubpixelHorizontalRgb = 2 // horizontal RGB
-const OutputSubpixelHorizontalBgr = 3 // horizontal BGR
-const OutputSubpixelVerticalRgb = 4 // vertical RGB
-const OutputSubpixelVerticalBgr = 5 // vertical BGR
+// OutputModeEvent is the wl_output.mode event.
+type OutputModeEvent struct {
+	Flags   OutputMode
+	Width   int32
+	Height  int32
+	Refresh int32
+}
 
+func (OutputModeEvent) isOutputEvent() {}
 
-const OutputTransformNormal = 0 // no transform
-const OutputTransform90 = 1 // 90 degrees counter-clockwise
-const OutputTransform180 = 2 // 180 degrees counter-clockwise
-const OutputTransform270 = 3 // 270 degrees counter-clockwise
-const OutputTransformFlipped = 4 // 180 degree flip around a vertical axis
-const OutputTransformFlipped90 = 5 // flip and rotate 90 degrees counter-clockwise
-const OutputTransformFlipped180 = 6 // flip and rotate 180 degrees counter-clockwise
-const OutputTransformFlipped270 = 7 // flip and rotate 270 degrees counter-clockwise
+// OutputDoneEvent is the wl_output.done event.
+type OutputDoneEvent struct {
+}
 
+func (OutputDoneEvent) isOutputEvent() {}
 
-const OutputModeCurrent = 0x1 // indicates this is the current mode
-const OutputModePreferred = 0x2 // indicates this is the preferred mode
+// OutputScaleEvent is the wl_output.scale event.
+type OutputScaleEvent struct {
+	Factor int32
+}
 
-type OutputListener interface {
-    Geometry(x int32, y int32, physicalWidth int32, physicalHeight int32, subpixel int32, make string, model string, transform int32)
-    Mode(flags uint32, width int32, height int32, refresh int32)
-    Done()
-    Scale(factor int32)
+func (OutputScaleEvent) isOutputEvent() {}
+
+var OutputInterface = &Interface{
+	Name:    "wl_output",
+	Version: 3,
+	New:     func() Object { return &Output{} },
 }
 
 // An output describes part of the compositor geometry.  The
@@ -1422,127 +4649,326 @@ type OutputListener interface {
 // displays part of the compositor space.  This object is published
 // as global during start up, or when a monitor is hotplugged.
 type Output struct {
-    ObjectID
-    listener OutputListener
-}
-
-func (this *Output) AddListener(listener OutputListener) {
-    this.listener = listener
+	Proxy
+}
+
+// AddListener adds a listener for the events of the wl_output and returns
+// the function that removes it again.
+func (this *Output) AddListener(listener OutputListener) func() {
+	return this.Proxy.AddListener(listener)
+}
+
+// Subscribe returns a channel receiving the events of the wl_output as
+// they are dispatched, until ctx is done or the object is destroyed. See
+// the Subscribe function for how events are buffered.
+func (this *Output) Subscribe(ctx context.Context) <-chan OutputEvent {
+	return Subscribe[OutputEvent](&this.Proxy, ctx)
+}
+
+// Events returns the channel receiving the events of the wl_output until it
+// is destroyed. Every call returns the same channel, use Subscribe for
+// another receiver.
+func (this *Output) Events() <-chan OutputEvent {
+	return Events[OutputEvent](&this.Proxy)
+}
+
+func (this *Output) Interface() *Interface {
+	return OutputInterface
+}
+
+func (this *Output) Decode(ev *Event) (func(), error) {
+	switch ev.Opcode {
+	case 0:
+		x := ev.GetInt()
+		y := ev.GetInt()
+		physicalWidth := ev.GetInt()
+		physicalHeight := ev.GetInt()
+		subpixel := OutputSubpixel(ev.GetUint())
+		make_ := ev.GetString()
+		model := ev.GetString()
+		transform := OutputTransform(ev.GetUint())
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(OutputListener); ok {
+					listener.Geometry(x, y, physicalWidth, physicalHeight, subpixel, make_, model, transform)
+				}
+			}
+			this.Proxy.Publish(OutputGeometryEvent{X: x, Y: y, PhysicalWidth: physicalWidth, PhysicalHeight: physicalHeight, Subpixel: subpixel, Make: make_, Model: model, Transform: transform})
+		}, nil
+	case 1:
+		flags := OutputMode(ev.GetUint())
+		width := ev.GetInt()
+		height := ev.GetInt()
+		refresh := ev.GetInt()
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(OutputListener); ok {
+					listener.Mode(flags, width, height, refresh)
+				}
+			}
+			this.Proxy.Publish(OutputModeEvent{Flags: flags, Width: width, Height: height, Refresh: refresh})
+		}, nil
+	case 2:
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(OutputListener); ok {
+					listener.Done()
+				}
+			}
+			this.Proxy.Publish(OutputDoneEvent{})
+		}, nil
+	case 3:
+		factor := ev.GetInt()
+		if err := ev.Err(); err != nil {
+			return nil, err
+		}
+		return func() {
+			for _, listener := range this.Proxy.Listeners() {
+				if listener, ok := listener.(OutputListener); ok {
+					listener.Scale(factor)
+				}
+			}
+			this.Proxy.Publish(OutputScaleEvent{Factor: factor})
+		}, nil
+	}
+	return nil, ev.InvalidOpcode()
+}
+
+func (this *Output) EventFds(opcode uint16) int {
+	return 0
 }
 
 // Using this request a client can tell the server that it is not going to
 // use the output object anymore.
 func (this *Output) Release() error {
-    return nil
+	if this.Proxy.Version() < OutputReleaseSinceVersion {
+		return UnsupportedVersion("wl_output.release", OutputReleaseSinceVersion, this.Proxy.Version())
+	}
+	msg := NewMessage(this.ObjectID, 0)
+	return this.Proxy.SendDestructor(msg)
 }
 
-
+const (
+	RegionDestroySinceVersion  = 1
+	RegionAddSinceVersion      = 1
+	RegionSubtractSinceVersion = 1
+)
 
 type RegionListener interface {
 }
 
+var RegionInterface = &Interface{
+	Name:    "wl_region",
+	Version: 1,
+	New:     func() Object { return &Region{} },
+}
+
 // A region object describes an area.
-// 
+//
 // Region objects are used to describe the opaque and input
 // regions of a surface.
 type Region struct {
-    ObjectID
-    listener RegionListener
+	Proxy
+}
+
+// AddListener adds a listener for the events of the wl_region and returns
+// the function that removes it again.
+func (this *Region) AddListener(listener RegionListener) func() {
+	return this.Proxy.AddListener(listener)
+}
+
+func (this *Region) Interface() *Interface {
+	return RegionInterface
 }
 
-func (this *Region) AddListener(listener RegionListener) {
-    this.listener = listener
+func (this *Region) Decode(ev *Event) (func(), error) {
+	return nil, ev.InvalidOpcode()
+}
+
+func (this *Region) EventFds(opcode uint16) int {
+	return 0
 }
 
 // Destroy the region.  This will invalidate the object ID.
 func (this *Region) Destroy() error {
-    return nil
+	msg := NewMessage(this.ObjectID, 0)
+	return this.Proxy.SendDestructor(msg)
 }
 
 // Add the specified rectangle to the region.
 func (this *Region) Add(x int32, y int32, width int32, height int32) error {
-    return nil
+	msg := NewMessage(this.ObjectID, 1)
+	msg.PutInt(x)
+	msg.PutInt(y)
+	msg.PutInt(width)
+	msg.PutInt(height)
+	return this.Proxy.Send(msg)
 }
 
 // Subtract the specified rectangle from the region.
 func (this *Region) Subtract(x int32, y int32, width int32, height int32) error {
-    return nil
+	msg := NewMessage(this.ObjectID, 2)
+	msg.PutInt(x)
+	msg.PutInt(y)
+	msg.PutInt(width)
+	msg.PutInt(height)
+	return this.Proxy.Send(msg)
 }
 
+type SubcompositorError uint32
 
+const SubcompositorErrorBadSurface SubcompositorError = 0 // the to-be sub-surface is invalid
 
+func (e SubcompositorError) String() string {
+	switch e {
+	case SubcompositorErrorBadSurface:
+		return "SubcompositorErrorBadSurface"
+	}
+	return EnumString("SubcompositorError", uint32(e))
+}
 
-const SubcompositorErrorBadSurface = 0 // the to-be sub-surface is invalid
+const (
+	SubcompositorDestroySinceVersion       = 1
+	SubcompositorGetSubsurfaceSinceVersion = 1
+)
 
 type SubcompositorListener interface {
 }
 
+var SubcompositorInterface = &Interface{
+	Name:    "wl_subcompositor",
+	Version: 1,
+	New:     func() Object { return &Subcompositor{} },
+	Errors: map[uint32]string{
+		uint32(SubcompositorErrorBadSurface): "SubcompositorErrorBadSurface",
+	},
+}
+
 // The global interface exposing sub-surface compositing capabilities.
 // A wl_surface, that has sub-surfaces associated, is called the
 // parent surface. Sub-surfaces can be arbitrarily nested and create
 // a tree of sub-surfaces.
-// 
+//
 // The root surface in a tree of sub-surfaces is the main
 // surface. The main surface cannot be a sub-surface, because
 // sub-surfaces must always have a parent.
-// 
+//
 // A main surface with its sub-surfaces forms a (compound) window.
 // For window management purposes, this set of wl_surface objects is
 // to be considered as a single window, and it should also behave as
 // such.
-// 
+//
 // The aim of sub-surfaces is to offload some of the compositing work
 // within a window from clients to the compositor. A prime example is
 // a video player with decorations and video in separate wl_surface
 // objects. This should allow the compositor to pass YUV video buffer
 // processing to dedicated overlay hardware when possible.
 type Subcompositor struct {
-    ObjectID
-    listener SubcompositorListener
+	Proxy
+}
+
+// AddListener adds a listener for the events of the wl_subcompositor and returns
+// the function that removes it again.
+func (this *Subcompositor) AddListener(listener SubcompositorListener) func() {
+	return this.Proxy.AddListener(listener)
+}
+
+func (this *Subcompositor) Interface() *Interface {
+	return SubcompositorInterface
+}
+
+func (this *Subcompositor) Decode(ev *Event) (func(), error) {
+	return nil, ev.InvalidOpcode()
 }
 
-func (this *Subcompositor) AddListener(listener SubcompositorListener) {
-    this.listener = listener
+func (this *Subcompositor) EventFds(opcode uint16) int {
+	return 0
 }
 
 // Informs the server that the client will not be using this
 // protocol object anymore. This does not affect any other
 // objects, wl_subsurface objects included.
 func (this *Subcompositor) Destroy() error {
-    return nil
+	msg := NewMessage(this.ObjectID, 0)
+	return this.Proxy.SendDestructor(msg)
 }
 
 // Create a sub-surface interface for the given surface, and
 // associate it with the given parent surface. This turns a
 // plain wl_surface into a sub-surface.
-// 
+//
 // The to-be sub-surface must not already have another role, and it
 // must not have an existing wl_subsurface object. Otherwise a protocol
 // error is raised.
-func (this *Subcompositor) GetSubsurface(surface uint32, parent uint32) (*Subsurface, error) {
-    return nil, nil
-}
-
-
-
-
-const SubsurfaceErrorBadSurface = 0 // wl_surface is not a sibling or the parent
+func (this *Subcompositor) GetSubsurface(surface *Surface, parent *Surface) (*Subsurface, error) {
+	if surface == nil {
+		return nil, NullArgument("wl_subcompositor.get_subsurface", "surface")
+	}
+	if parent == nil {
+		return nil, NullArgument("wl_subcompositor.get_subsurface", "parent")
+	}
+	ret := &Subsurface{}
+	msg := NewMessage(this.ObjectID, 1)
+	msg.PutNewID(ret, this.Proxy.Version())
+	msg.PutObject(surface.ObjectID)
+	msg.PutObject(parent.ObjectID)
+	if err := this.Proxy.Send(msg); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+type SubsurfaceError uint32
+
+const SubsurfaceErrorBadSurface SubsurfaceError = 0 // wl_surface is not a sibling or the parent
+
+func (e SubsurfaceError) String() string {
+	switch e {
+	case SubsurfaceErrorBadSurface:
+		return "SubsurfaceErrorBadSurface"
+	}
+	return EnumString("SubsurfaceError", uint32(e))
+}
+
+const (
+	SubsurfaceDestroySinceVersion     = 1
+	SubsurfaceSetPositionSinceVersion = 1
+	SubsurfacePlaceAboveSinceVersion  = 1
+	SubsurfacePlaceBelowSinceVersion  = 1
+	SubsurfaceSetSyncSinceVersion     = 1
+	SubsurfaceSetDesyncSinceVersion   = 1
+)
 
 type SubsurfaceListener interface {
 }
 
+var SubsurfaceInterface = &Interface{
+	Name:    "wl_subsurface",
+	Version: 1,
+	New:     func() Object { return &Subsurface{} },
+	Errors: map[uint32]string{
+		uint32(SubsurfaceErrorBadSurface): "SubsurfaceErrorBadSurface",
+	},
+}
+
 // An additional interface to a wl_surface object, which has been
 // made a sub-surface. A sub-surface has one parent surface. A
 // sub-surface's size and position are not limited to that of the parent.
 // Particularly, a sub-surface is not automatically clipped to its
 // parent's area.
-// 
+//
 // A sub-surface becomes mapped, when a non-NULL wl_buffer is applied
 // and the parent surface is mapped. The order of which one happens
 // first is irrelevant. A sub-surface is hidden if the parent becomes
 // hidden, or if a NULL wl_buffer is applied. These rules apply
 // recursively through the tree of surfaces.
-// 
+//
 // The behaviour of a wl_surface.commit request on a sub-surface
 // depends on the sub-surface's mode. The possible modes are
 // synchronized and desynchronized, see methods
@@ -1551,7 +4977,7 @@ type SubsurfaceListener interface {
 // state gets applied, and desynchronized mode applies the pending
 // wl_surface state directly. A sub-surface is initially in the
 // synchronized mode.
-// 
+//
 // Sub-surfaces have also other kind of state, which is managed by
 // wl_subsurface requests, as opposed to wl_surface requests. This
 // state includes the sub-surface position relative to the parent
@@ -1560,33 +4986,46 @@ type SubsurfaceListener interface {
 // .place_below). This state is applied when the parent surface's
 // wl_surface state is applied, regardless of the sub-surface's mode.
 // As the exception, set_sync and set_desync are effective immediately.
-// 
+//
 // The main surface can be thought to be always in desynchronized mode,
 // since it does not have a parent in the sub-surfaces sense.
-// 
+//
 // Even if a sub-surface is in desynchronized mode, it will behave as
 // in synchronized mode, if its parent surface behaves as in
 // synchronized mode. This rule is applied recursively throughout the
 // tree of surfaces. This means, that one can set a sub-surface into
 // synchronized mode, and then assume that all its child and grand-child
 // sub-surfaces are synchronized, too, without explicitly setting them.
-// 
+//
 // If the wl_surface associated with the wl_subsurface is destroyed, the
 // wl_subsurface object becomes inert. Note, that destroying either object
 // takes effect immediately. If you need to synchronize the removal
 // of a sub-surface to the parent surface update, unmap the sub-surface
 // first by attaching a NULL wl_buffer, update parent, and then destroy
 // the sub-surface.
-// 
+//
 // If the parent wl_surface object is destroyed, the sub-surface is
 // unmapped.
 type Subsurface struct {
-    ObjectID
-    listener SubsurfaceListener
+	Proxy
 }
 
-func (this *Subsurface) AddListener(listener SubsurfaceListener) {
-    this.listener = listener
+// AddListener adds a listener for the events of the wl_subsurface and returns
+// the function that removes it again.
+func (this *Subsurface) AddListener(listener SubsurfaceListener) func() {
+	return this.Proxy.AddListener(listener)
+}
+
+func (this *Subsurface) Interface() *Interface {
+	return SubsurfaceInterface
+}
+
+func (this *Subsurface) Decode(ev *Event) (func(), error) {
+	return nil, ev.InvalidOpcode()
+}
+
+func (this *Subsurface) EventFds(opcode uint16) int {
+	return 0
 }
 
 // The sub-surface interface is removed from the wl_surface object
@@ -1595,7 +5034,8 @@ func (this *Subsurface) AddListener(listener SubsurfaceListener) {
 // to the parent is deleted, and the wl_surface loses its role as
 // a sub-surface. The wl_surface is unmapped.
 func (this *Subsurface) Destroy() error {
-    return nil
+	msg := NewMessage(this.ObjectID, 0)
+	return this.Proxy.SendDestructor(msg)
 }
 
 // This schedules a sub-surface position change.
@@ -1603,19 +5043,22 @@ func (this *Subsurface) Destroy() error {
 // corner pixel) will be at the location x, y of the parent surface
 // coordinate system. The coordinates are not restricted to the parent
 // surface area. Negative values are allowed.
-// 
+//
 // The scheduled coordinates will take effect whenever the state of the
 // parent surface is applied. When this happens depends on whether the
 // parent surface is in synchronized mode or not. See
 // wl_subsurface.set_sync and wl_subsurface.set_desync for details.
-// 
+//
 // If more than one set_position request is invoked by the client before
 // the commit of the parent surface, the position of a new request always
 // replaces the scheduled position from any previous request.
-// 
+//
 // The initial position is 0, 0.
 func (this *Subsurface) SetPosition(x int32, y int32) error {
-    return nil
+	msg := NewMessage(this.ObjectID, 1)
+	msg.PutInt(x)
+	msg.PutInt(y)
+	return this.Proxy.Send(msg)
 }
 
 // This sub-surface is taken from the stack, and put back just
@@ -1623,29 +5066,39 @@ func (this *Subsurface) SetPosition(x int32, y int32) error {
 // The reference surface must be one of the sibling surfaces, or the
 // parent surface. Using any other surface, including this sub-surface,
 // will cause a protocol error.
-// 
+//
 // The z-order is double-buffered. Requests are handled in order and
 // applied immediately to a pending state. The final pending state is
 // copied to the active state the next time the state of the parent
 // surface is applied. When this happens depends on whether the parent
 // surface is in synchronized mode or not. See wl_subsurface.set_sync and
 // wl_subsurface.set_desync for details.
-// 
+//
 // A new sub-surface is initially added as the top-most in the stack
 // of its siblings and parent.
-func (this *Subsurface) PlaceAbove(sibling uint32) error {
-    return nil
+func (this *Subsurface) PlaceAbove(sibling *Surface) error {
+	if sibling == nil {
+		return NullArgument("wl_subsurface.place_above", "sibling")
+	}
+	msg := NewMessage(this.ObjectID, 2)
+	msg.PutObject(sibling.ObjectID)
+	return this.Proxy.Send(msg)
 }
 
 // The sub-surface is placed just below the reference surface.
 // See wl_subsurface.place_above.
-func (this *Subsurface) PlaceBelow(sibling uint32) error {
-    return nil
+func (this *Subsurface) PlaceBelow(sibling *Surface) error {
+	if sibling == nil {
+		return NullArgument("wl_subsurface.place_below", "sibling")
+	}
+	msg := NewMessage(this.ObjectID, 3)
+	msg.PutObject(sibling.ObjectID)
+	return this.Proxy.Send(msg)
 }
 
 // Change the commit behaviour of the sub-surface to synchronized
 // mode, also described as the parent dependent mode.
-// 
+//
 // In synchronized mode, wl_surface.commit on a sub-surface will
 // accumulate the committed state in a cache, but the state will
 // not be applied and hence will not change the compositor output.
@@ -1654,32 +5107,33 @@ func (this *Subsurface) PlaceBelow(sibling uint32) error {
 // updates of the parent and all its synchronized sub-surfaces.
 // Applying the cached state will invalidate the cache, so further
 // parent surface commits do not (re-)apply old state.
-// 
+//
 // See wl_subsurface for the recursive effect of this mode.
 func (this *Subsurface) SetSync() error {
-    return nil
+	msg := NewMessage(this.ObjectID, 4)
+	return this.Proxy.Send(msg)
 }
 
 // Change the commit behaviour of the sub-surface to desynchronized
 // mode, also described as independent or freely running mode.
-// 
+//
 // In desynchronized mode, wl_surface.commit on a sub-surface will
 // apply the pending state directly, without caching, as happens
 // normally with a wl_surface. Calling wl_surface.commit on the
 // parent surface has no effect on the sub-surface's wl_surface
 // state. This mode allows a sub-surface to be updated on its own.
-// 
+//
 // If cached state exists when wl_surface.commit is called in
 // desynchronized mode, the pending state is added to the cached
 // state, and applied as a whole. This invalidates the cache.
-// 
+//
 // Note: even if a sub-surface is set to desynchronized, a parent
 // sub-surface may override it to behave as synchronized. For details,
 // see wl_subsurface.
-// 
+//
 // If a surface's parent surface behaves as desynchronized, then
 // the cached state is applied on set_desync.
 func (this *Subsurface) SetDesync() error {
-    return nil
+	msg := NewMessage(this.ObjectID, 5)
+	return this.Proxy.Send(msg)
 }
-
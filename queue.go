@@ -0,0 +1,154 @@
+package wl
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// EventQueue holds the events of the objects assigned to it until they are
+// dispatched with Client.DispatchQueue. Every client has a default queue,
+// dispatched by Client.Dispatch, which objects use unless they are given
+// another one. A goroutine that only dispatches its own queue, such as a
+// render loop waiting for frame callbacks, leaves the events of every other
+// queue alone.
+type EventQueue struct {
+	client *Client
+	events []func()
+	// dispatched counts the events dispatched from the queue so far, so
+	// waiting dispatchers notice events other goroutines handled for them.
+	dispatched uint64
+}
+
+// NewEventQueue creates an empty event queue for the client.
+func (c *Client) NewEventQueue() *EventQueue {
+	return &EventQueue{client: c}
+}
+
+// DispatchQueue is Dispatch for the events of q.
+func (c *Client) DispatchQueue(q *EventQueue) error {
+	return c.dispatchQueue(context.Background(), q)
+}
+
+// DispatchQueuePending dispatches the events already waiting on q without
+// blocking to read more.
+func (c *Client) DispatchQueuePending(q *EventQueue) error {
+	if err := c.connected(); err != nil {
+		return err
+	}
+	if q.client != c {
+		return errors.New("event queue belongs to another client")
+	}
+	if err := c.dispatchEvents(c.displayQueue); err != nil {
+		return err
+	}
+	return c.dispatchEvents(q)
+}
+
+// dispatchEvents dispatches the events waiting on q, returning the error of
+// the connection once it has failed.
+func (c *Client) dispatchEvents(q *EventQueue) error {
+	for {
+		c.mutex.Lock()
+		if c.err != nil {
+			c.mutex.Unlock()
+			return c.err
+		}
+		if len(q.events) == 0 {
+			c.mutex.Unlock()
+			return nil
+		}
+		deliver := q.events[0]
+		q.events = q.events[1:]
+		c.mutex.Unlock()
+
+		// listeners run without the mutex so they can send requests
+		deliver()
+
+		c.mutex.Lock()
+		q.dispatched++
+		c.cond.Broadcast()
+		c.mutex.Unlock()
+	}
+}
+
+// RoundtripQueue is Roundtrip for the events of q: it returns once every
+// event caused by the requests sent so far has been dispatched from q.
+func (c *Client) RoundtripQueue(ctx context.Context, q *EventQueue) (uint32, error) {
+	if err := c.connected(); err != nil {
+		return 0, err
+	}
+	if q.client != c {
+		return 0, errors.New("event queue belongs to another client")
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	// the listener has to be in place before the request is sent, as
+	// another goroutine may dispatch the callback right away
+	done := &syncListener{fired: make(chan struct{})}
+	cb := &Callback{}
+	cb.AddListener(done)
+	msg := NewMessage(c.display.ObjectID, 0)
+	msg.PutNewID(cb, 1)
+	if err := WithQueue(c.display, q).Proxy.Send(msg); err != nil {
+		return 0, err
+	}
+	err := c.dispatchUntil(ctx, q, func() bool {
+		select {
+		case <-done.fired:
+			return true
+		default:
+			return false
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+	return done.serial, nil
+}
+
+// SetQueue routes the events of the object that have not been read yet to
+// q, or to the default queue if q is nil. Objects created by the requests
+// and events of the object are assigned to the same queue. Events may
+// already have arrived before SetQueue is called, use WithQueue to create
+// objects on a queue without that race.
+func (p *Proxy) SetQueue(q *EventQueue) {
+	defer p.lock()()
+	p.queue = q
+}
+
+// WithQueue returns a wrapper for obj whose requests create their objects
+// on q, like libwayland's wl_proxy_create_wrapper. Requests sent through
+// the wrapper are sent on behalf of obj, whose own events keep going to
+// its own queue. For example
+//
+//	frame, err := WithQueue(surface, renderQueue).Frame()
+//
+// creates a frame callback whose done event is dispatched by
+// DispatchQueue(renderQueue) only. The wrapper receives no events and
+// cannot be destroyed.
+func WithQueue[T proxied](obj T, q *EventQueue) T {
+	wrapper := obj.Interface().New().(T)
+	p := obj.proxy()
+	defer p.lock()()
+	w := wrapper.proxy()
+	w.ObjectID = p.ObjectID
+	w.client = p.client
+	w.version = p.version
+	w.queue = q
+	w.wrapped = p
+	if p.wrapped != nil {
+		w.wrapped = p.wrapped
+	}
+	return wrapper
+}
+
+// eventQueue returns the queue the events of the object are routed to.
+func (p *Proxy) eventQueue() *EventQueue {
+	defer p.lock()()
+	if p.queue == nil {
+		return p.client.queue
+	}
+	return p.queue
+}
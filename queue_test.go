@@ -0,0 +1,153 @@
+package wl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type doneRecorder struct {
+	serials []uint32
+}
+
+func (r *doneRecorder) Done(callbackData uint32) {
+	r.serials = append(r.serials, callbackData)
+}
+
+func TestEventQueue(t *testing.T) {
+	c, server := socketPair(t)
+	registry, err := c.Display().GetRegistry()
+	require.NoError(t, err)
+	rec := &registryRecorder{globals: make(map[uint32]string)}
+	registry.AddListener(rec)
+	compositor, err := Bind[*Compositor](registry, 1, 4)
+	require.NoError(t, err)
+	surface, err := compositor.CreateSurface()
+	require.NoError(t, err)
+
+	q := c.NewEventQueue()
+	frame, err := WithQueue(surface, q).Frame()
+	require.NoError(t, err)
+	assert.Equal(t, q, frame.queue)
+	assert.Nil(t, surface.queue)
+	done := &doneRecorder{}
+	frame.AddListener(done)
+
+	global := NewMessage(registry.ObjectID, 0)
+	global.PutUint(2)
+	global.PutString("wl_seat")
+	global.PutUint(5)
+	fired := NewMessage(frame.ObjectID, 0)
+	fired.PutUint(16)
+	_, err = server.Write(append(global.bytes(), fired.bytes()...))
+	require.NoError(t, err)
+
+	// the render queue gets its frame callback without draining the
+	// registry event waiting on the default queue
+	require.NoError(t, c.DispatchQueue(q))
+	assert.Equal(t, []uint32{16}, done.serials)
+	assert.Equal(t, 0, rec.events)
+
+	require.NoError(t, c.DispatchPending())
+	assert.Equal(t, 1, rec.events)
+	assert.Equal(t, []uint32{16}, done.serials)
+}
+
+func TestSetQueue(t *testing.T) {
+	c, server := socketPair(t)
+	registry, err := c.Display().GetRegistry()
+	require.NoError(t, err)
+	rec := &registryRecorder{globals: make(map[uint32]string)}
+	registry.AddListener(rec)
+	q := c.NewEventQueue()
+	registry.SetQueue(q)
+
+	sendGlobal(t, server, registry, 1, "wl_compositor", 4)
+	require.NoError(t, c.DispatchQueue(q))
+	assert.Equal(t, map[uint32]string{1: "wl_compositor"}, rec.globals)
+}
+
+func TestQueueInheritance(t *testing.T) {
+	c, server := socketPair(t)
+	registry, err := c.Display().GetRegistry()
+	require.NoError(t, err)
+	manager, err := Bind[*DataDeviceManager](registry, 1, 3)
+	require.NoError(t, err)
+	seat, err := Bind[*Seat](registry, 2, 5)
+	require.NoError(t, err)
+	q := c.NewEventQueue()
+	device, err := WithQueue(manager, q).GetDataDevice(seat)
+	require.NoError(t, err)
+
+	// objects created by the server join the queue of their creator
+	msg := NewMessage(device.ObjectID, 0)
+	msg.PutObject(serverIDStart)
+	_, err = server.Write(msg.bytes())
+	require.NoError(t, err)
+	require.NoError(t, c.DispatchQueue(q))
+	offer, ok := c.objects[serverIDStart].(*DataOffer)
+	require.True(t, ok)
+	assert.Equal(t, q, offer.queue)
+	assert.Equal(t, uint32(3), offer.Version())
+}
+
+func TestQueueWrapper(t *testing.T) {
+	c, _ := socketPair(t)
+	q := c.NewEventQueue()
+	wrapper := WithQueue(c.Display(), q)
+	assert.Equal(t, c.Display().ObjectID, wrapper.ObjectID)
+	_, ok := c.objects[wrapper.ObjectID].(*Display)
+	require.True(t, ok)
+	assert.NotEqual(t, wrapper, c.objects[wrapper.ObjectID])
+	assert.Error(t, wrapper.Proxy.SendDestructor(NewMessage(wrapper.ObjectID, 0)))
+
+	other, _ := socketPair(t)
+	assert.Error(t, c.DispatchQueue(other.NewEventQueue()))
+	_, err := c.RoundtripQueue(context.Background(), other.NewEventQueue())
+	assert.Error(t, err)
+}
+
+func TestDisplayEventsOnCustomQueue(t *testing.T) {
+	c, server := socketPair(t)
+	q := c.NewEventQueue()
+	registry, err := WithQueue(c.Display(), q).GetRegistry()
+	require.NoError(t, err)
+	compositor, err := Bind[*Compositor](registry, 1, 4)
+	require.NoError(t, err)
+	surface, err := compositor.CreateSurface()
+	require.NoError(t, err)
+	require.NoError(t, surface.Destroy())
+
+	// delete_id is handled even though only the custom queue is dispatched
+	del := NewMessage(c.Display().ObjectID, 1)
+	del.PutUint(uint32(surface.ObjectID))
+	global := NewMessage(registry.ObjectID, 0)
+	global.PutUint(2)
+	global.PutString("wl_seat")
+	global.PutUint(5)
+	_, err = server.Write(append(del.bytes(), global.bytes()...))
+	require.NoError(t, err)
+	require.NoError(t, c.DispatchQueue(q))
+	assert.NotContains(t, c.objects, surface.ObjectID)
+
+	msg := NewMessage(c.Display().ObjectID, 0)
+	msg.PutObject(registry.ObjectID)
+	msg.PutUint(uint32(DisplayErrorInvalidMethod))
+	msg.PutString("invalid method")
+	_, err = server.Write(msg.bytes())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = c.RoundtripQueue(ctx, q)
+	var perr *ProtocolError
+	require.True(t, errors.As(err, &perr))
+	assert.Equal(t, "invalid method", perr.Message)
+	_, err = c.Display().GetRegistry()
+	assert.Equal(t, perr, err)
+	assert.Equal(t, perr, c.DispatchQueuePending(q))
+}
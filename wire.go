@@ -0,0 +1,268 @@
+package wl
+
+import (
+	"encoding/binary"
+	"github.com/pkg/errors"
+	"os"
+	"syscall"
+)
+
+// headerSize is the size of the object ID and opcode/size words that
+// precede every message on the wire.
+const headerSize = 8
+
+// maxFdsPerMessage bounds the number of file descriptors the server may
+// attach to a single read, matching the limit libwayland uses.
+const maxFdsPerMessage = 28
+
+// maxMessageSize is the largest message the wire format can describe; the
+// size is carried in the upper 16 bits of the second header word.
+const maxMessageSize = 4096
+
+// Message is a single request being marshaled for the wire by generated
+// code. Arguments are appended in order and padded to 32 bits; the size
+// half of the header is filled in by bytes once every argument has been
+// written. File descriptors do not appear in the message body and are sent
+// alongside it as ancillary data.
+type Message struct {
+	opcode uint16
+	buf    []byte
+	files  []*os.File
+	// newObj is the object created by the request, registered under a
+	// fresh ID written at newOff when the message is sent.
+	newObj     Object
+	newVersion uint32
+	newOff     int
+}
+
+func NewMessage(id ObjectID, opcode uint16) *Message {
+	m := &Message{opcode: opcode, buf: make([]byte, headerSize, 64)}
+	binary.NativeEndian.PutUint32(m.buf[0:], uint32(id))
+	return m
+}
+
+func (m *Message) PutUint(v uint32) {
+	m.buf = binary.NativeEndian.AppendUint32(m.buf, v)
+}
+
+func (m *Message) PutInt(v int32) {
+	m.PutUint(uint32(v))
+}
+
+func (m *Message) PutFixed(v Fixed) {
+	m.PutUint(uint32(v))
+}
+
+func (m *Message) PutObject(id ObjectID) {
+	m.PutUint(uint32(id))
+}
+
+// PutNewID writes the ID of the object created by the request. The ID is
+// only allocated, and obj registered with the given version, once the
+// message is sent, so IDs reach the server in the order they were
+// allocated even when requests are sent from several goroutines.
+func (m *Message) PutNewID(obj Object, version uint32) {
+	m.newObj = obj
+	m.newVersion = version
+	m.newOff = len(m.buf)
+	m.PutUint(0)
+}
+
+// PutString writes the length (including the NUL terminator), the string
+// contents and the terminator, padded to a 32 bit boundary.
+func (m *Message) PutString(s string) {
+	m.PutUint(uint32(len(s) + 1))
+	m.buf = append(m.buf, s...)
+	m.buf = append(m.buf, 0)
+	m.pad()
+}
+
+// PutNullString writes s, or the null string if s is nil.
+func (m *Message) PutNullString(s *string) {
+	if s == nil {
+		m.PutUint(0)
+		return
+	}
+	m.PutString(*s)
+}
+
+// PutArray writes the length in bytes followed by the contents, padded to
+// a 32 bit boundary.
+func (m *Message) PutArray(a []byte) {
+	m.PutUint(uint32(len(a)))
+	m.buf = append(m.buf, a...)
+	m.pad()
+}
+
+func (m *Message) PutFd(f *os.File) {
+	m.files = append(m.files, f)
+}
+
+// fds returns the descriptors to pass with the message. The files are kept
+// referenced by the message until it has been sent so they cannot be closed
+// by a finalizer in the meantime.
+func (m *Message) fds() []int {
+	fds := make([]int, len(m.files))
+	for i, f := range m.files {
+		fds[i] = int(f.Fd())
+	}
+	return fds
+}
+
+func (m *Message) pad() {
+	for len(m.buf)%4 != 0 {
+		m.buf = append(m.buf, 0)
+	}
+}
+
+// bytes completes the header and returns the encoded message.
+func (m *Message) bytes() []byte {
+	binary.NativeEndian.PutUint32(m.buf[4:], uint32(len(m.buf))<<16|uint32(m.opcode))
+	return m.buf
+}
+
+// Event is a single message received from the server, decoded by generated
+// code. Arguments are decoded in order with the Get methods; the first
+// decoding failure is recorded and returned by Err, and every later Get
+// returns a zero value. The file descriptors that arrived with the message
+// are taken from the connection when the event is read and handed out by
+// GetFd.
+type Event struct {
+	Sender ObjectID
+	Opcode uint16
+	data   []byte
+	fds    []int
+	off    int
+	err    error
+}
+
+func (ev *Event) next(n int) []byte {
+	if ev.err != nil {
+		return nil
+	}
+	if n < 0 || len(ev.data)-ev.off < n {
+		ev.err = errors.Errorf("event %d on object %d is truncated", ev.Opcode, ev.Sender)
+		return nil
+	}
+	b := ev.data[ev.off : ev.off+n]
+	ev.off += (n + 3) &^ 3
+	if ev.off > len(ev.data) {
+		ev.off = len(ev.data)
+	}
+	return b
+}
+
+func (ev *Event) GetUint() uint32 {
+	b := ev.next(4)
+	if b == nil {
+		return 0
+	}
+	return binary.NativeEndian.Uint32(b)
+}
+
+func (ev *Event) GetInt() int32 {
+	return int32(ev.GetUint())
+}
+
+func (ev *Event) GetFixed() Fixed {
+	return Fixed(ev.GetUint())
+}
+
+func (ev *Event) GetObject() ObjectID {
+	return ObjectID(ev.GetUint())
+}
+
+// GetProxy resolves an object argument to its proxy. Null objects are
+// returned as nil and objects the client does not know about or has
+// destroyed as their bare ObjectID, which typed arguments then see as nil.
+func (ev *Event) GetProxy(c *Client) Object {
+	id := ev.GetObject()
+	if id == 0 {
+		return nil
+	}
+	if obj, ok := c.lookup(id); ok {
+		if _, dead := obj.(*zombie); !dead {
+			return obj
+		}
+	}
+	return id
+}
+
+// GetString decodes a string that is not allowed to be null. A null string
+// fails the event, as it does in libwayland.
+func (ev *Event) GetString() string {
+	str, ok := ev.decodeString()
+	if !ok && ev.err == nil {
+		ev.err = errors.Errorf("null string in event %d on object %d for an argument that is not nullable", ev.Opcode, ev.Sender)
+	}
+	return str
+}
+
+// GetNullString decodes a string that is allowed to be null, returning nil
+// for the null string.
+func (ev *Event) GetNullString() *string {
+	str, ok := ev.decodeString()
+	if !ok {
+		return nil
+	}
+	return &str
+}
+
+// decodeString decodes a string argument, reporting false if it was the
+// null string or could not be decoded.
+func (ev *Event) decodeString() (string, bool) {
+	n := int(ev.GetUint())
+	if n == 0 {
+		return "", false
+	}
+	b := ev.next(n)
+	if b == nil {
+		return "", false
+	}
+	if b[n-1] != 0 {
+		ev.err = errors.Errorf("string in event %d on object %d is not NUL terminated", ev.Opcode, ev.Sender)
+		return "", false
+	}
+	return string(b[:n-1]), true
+}
+
+func (ev *Event) GetArray() []byte {
+	n := int(ev.GetUint())
+	b := ev.next(n)
+	if b == nil {
+		return nil
+	}
+	return append([]byte(nil), b...)
+}
+
+func (ev *Event) GetFd() *os.File {
+	if ev.err != nil {
+		return nil
+	}
+	if len(ev.fds) == 0 {
+		ev.err = errors.Errorf("event %d on object %d is missing a file descriptor", ev.Opcode, ev.Sender)
+		return nil
+	}
+	fd := ev.fds[0]
+	ev.fds = ev.fds[1:]
+	return os.NewFile(uintptr(fd), "wayland-fd")
+}
+
+// closeFds closes the file descriptors of an event that is dropped.
+func (ev *Event) closeFds() {
+	for _, fd := range ev.fds {
+		syscall.Close(fd)
+	}
+	ev.fds = nil
+}
+
+// Err returns the first error encountered while decoding the event.
+func (ev *Event) Err() error {
+	return ev.err
+}
+
+// InvalidOpcode returns the error for an event whose opcode the receiving
+// interface does not define.
+func (ev *Event) InvalidOpcode() error {
+	return errors.Errorf("invalid event opcode %d for object %d", ev.Opcode, ev.Sender)
+}
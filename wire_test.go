@@ -0,0 +1,26 @@
+package wl
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageEncoding(t *testing.T) {
+	msg := NewMessage(ObjectID(3), 2)
+	msg.PutInt(-1)
+	msg.PutString("wl_shm")
+	msg.PutArray([]byte{1, 2, 3, 4, 5})
+	data := msg.bytes()
+
+	// header + int + (len + "wl_shm\x00" padded to 8) + (len + 5 bytes padded to 8)
+	assert.Len(t, data, 8+4+4+8+4+8)
+	assert.Equal(t, uint32(3), binary.NativeEndian.Uint32(data[0:]))
+	assert.Equal(t, uint32(len(data))<<16|2, binary.NativeEndian.Uint32(data[4:]))
+	assert.Equal(t, uint32(0xffffffff), binary.NativeEndian.Uint32(data[8:]))
+	assert.Equal(t, uint32(7), binary.NativeEndian.Uint32(data[12:]))
+	assert.Equal(t, []byte("wl_shm\x00\x00"), data[16:24])
+	assert.Equal(t, uint32(5), binary.NativeEndian.Uint32(data[24:]))
+	assert.Equal(t, []byte{1, 2, 3, 4, 5, 0, 0, 0}, data[28:36])
+}
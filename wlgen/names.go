@@ -0,0 +1,145 @@
+package main
+
+import (
+	"github.com/serenize/snaker"
+	"go/token"
+	"strings"
+	"unicode"
+)
+
+// predeclared are the identifiers of the universe scope. Arguments named
+// after them would shadow the types used in generated signatures.
+var predeclared = map[string]bool{
+	"any": true, "bool": true, "byte": true, "comparable": true,
+	"complex64": true, "complex128": true, "error": true, "float32": true,
+	"float64": true, "int": true, "int8": true, "int16": true, "int32": true,
+	"int64": true, "rune": true, "string": true, "uint": true, "uint8": true,
+	"uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+	"true": true, "false": true, "iota": true, "nil": true,
+	"append": true, "cap": true, "clear": true, "close": true,
+	"complex": true, "copy": true, "delete": true, "imag": true, "len": true,
+	"make": true, "max": true, "min": true, "new": true, "panic": true,
+	"print": true, "println": true, "real": true, "recover": true,
+}
+
+// locals are the names generated methods use for their receiver and
+// variables, which arguments must not shadow.
+var locals = map[string]bool{
+	"this": true, "msg": true, "ret": true, "ev": true, "listener": true,
+	"ok": true,
+}
+
+// newIDParams are the parameters an untyped new_id argument is passed as,
+// which the other arguments of its request must not reuse.
+var newIDParams = map[string]bool{
+	"iface": true, "version": true,
+}
+
+// methods are the methods every generated type has, which requests must
+// not redeclare.
+var methods = map[string]bool{
+	"AddListener": true, "Interface": true, "Decode": true,
+	"EventFds": true, "ID": true, "Subscribe": true, "Events": true,
+}
+
+// identifier drops the characters of name that cannot appear in a Go
+// identifier and prefixes it with prefix if it is then empty or starts
+// with a digit.
+func identifier(name string, prefix string) string {
+	name = strings.Map(func(r rune) rune {
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, name)
+	if name == "" || unicode.IsDigit([]rune(name)[0]) {
+		name = prefix + name
+	}
+	return name
+}
+
+// isPackageName reports whether name is the name of a package generated
+// code may refer to.
+func isPackageName(name string) bool {
+	if name == "os" {
+		return true
+	}
+	for _, pkg := range index {
+		if pkg.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ArgName returns the Go parameter or variable name for an argument. Names
+// that are keywords, predeclared, used by the generated code itself, the
+// name of a package or one of the parameters of an untyped new_id in the
+// same request are suffixed with an underscore.
+func ArgName(arg *Arg) string {
+	name := identifier(snaker.SnakeToCamelLower(arg.Name), "x")
+	if name == "interface" {
+		name = "iface"
+	}
+	if token.IsKeyword(name) || predeclared[name] || locals[name] || isPackageName(name) ||
+		arg.withUntypedNewID && newIDParams[name] {
+		name += "_"
+	}
+	return name
+}
+
+// FieldName returns the Go struct field name for an event argument.
+func FieldName(arg *Arg) string {
+	return identifier(snaker.SnakeToCamel(arg.Name), "X")
+}
+
+// RequestName returns the Go method name for a request, suffixed with an
+// underscore if it would clash with a method every generated type has.
+func RequestName(name string) string {
+	name = identifier(snaker.SnakeToCamel(name), "X")
+	if methods[name] {
+		name += "_"
+	}
+	return name
+}
+
+// EventName returns the Go listener method name for an event.
+func EventName(name string) string {
+	return identifier(snaker.SnakeToCamel(name), "X")
+}
+
+// HandlerName returns the name of the field of a ListenerFuncs struct
+// holding the function for an event of iface, suffixed with Func if it
+// would clash with the listener method of another event.
+func HandlerName(iface *Interface, name string) string {
+	handler := "On" + EventName(name)
+	for _, ev := range iface.Events {
+		if EventName(ev.Name) == handler {
+			return handler + "Func"
+		}
+	}
+	return handler
+}
+
+// EntryName returns the Go constant name for an entry of the enum with the
+// Go type enum. Entries such as the 90 of wl_output.transform are only
+// valid identifiers thanks to the type name in front of them.
+func EntryName(enum string, entry string) string {
+	return enum + identifier(snaker.SnakeToCamel(entry), "")
+}
+
+// SinceName returns the name of the constant holding the version a request
+// or event of iface was introduced in. Events named like a request of the
+// same interface are told apart by an Event suffix on their name.
+func SinceName(iface *Interface, name string, event bool) string {
+	since := InterfaceName(iface.Name) + EventName(name)
+	if event {
+		for _, req := range iface.Requests {
+			if req.Name == name {
+				since += "Event"
+				break
+			}
+		}
+	}
+	return since + "SinceVersion"
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"github.com/pkg/errors"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// goPackage is a Go package generated from a protocol.
+type goPackage struct {
+	Name string
+	Path string
+	// types maps the Go type names in the package to the interfaces they
+	// were generated from.
+	types map[string]string
+}
+
+// runtimePath is the import path of the package providing Proxy, Client
+// and the wire encoding that generated code builds on.
+var runtimePath = "github.com/elliotmr/wl"
+
+// index maps every known protocol interface to the package it is
+// generated in, so references can be resolved across protocol files.
+var index = make(map[string]*goPackage)
+
+// current is the package being generated.
+var current *goPackage
+
+// indexProtocol records the interfaces of p as being generated in pkg,
+// failing if an interface is already known or two declarations generated
+// in pkg, such as the types of two interfaces, map to the same Go name.
+func indexProtocol(p *Protocol, pkg *goPackage) error {
+	if pkg.types == nil {
+		pkg.types = make(map[string]string)
+	}
+	declare := func(name string, owner string) error {
+		if other, ok := pkg.types[name]; ok {
+			return errors.Errorf("%s and %s both map to %s in %s, use -prefix to tell them apart", other, owner, name, pkg.Path)
+		}
+		pkg.types[name] = owner
+		return nil
+	}
+	for _, iface := range p.Interfaces {
+		if other, ok := index[iface.Name]; ok {
+			return errors.Errorf("interface %s is defined in both %s and %s", iface.Name, other.Path, pkg.Path)
+		}
+		if err := declare(InterfaceName(iface.Name), iface.Name); err != nil {
+			return err
+		}
+		index[iface.Name] = pkg
+	}
+	for _, iface := range p.Interfaces {
+		for _, d := range declarations(iface) {
+			if err := declare(d.name, d.owner); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// declaration is a package level name generated for an interface besides
+// its type, with a description of what it is generated for.
+type declaration struct {
+	name  string
+	owner string
+}
+
+// declarations returns the package level names generated for iface other
+// than its type, matching the template.
+func declarations(iface *Interface) []declaration {
+	ifn := InterfaceName(iface.Name)
+	decls := []declaration{
+		{ifn + "Interface", iface.Name + " interface variable"},
+		{ifn + "Listener", iface.Name + " listener"},
+	}
+	for _, enum := range iface.Enums {
+		owner := iface.Name + "." + enum.Name
+		enn := EnumTypeName(iface.Name, enum.Name)
+		decls = append(decls, declaration{enn, owner + " enum"})
+		for _, entry := range enum.Entries {
+			name := EntryName(enn, entry.Name)
+			decls = append(decls, declaration{name, owner + "." + entry.Name + " entry"})
+			if entry.Since != "" {
+				decls = append(decls, declaration{name + "SinceVersion", owner + "." + entry.Name + " since constant"})
+			}
+		}
+	}
+	for _, req := range iface.Requests {
+		decls = append(decls, declaration{SinceName(iface, req.Name, false), iface.Name + "." + req.Name + " since constant"})
+	}
+	for _, ev := range iface.Events {
+		decls = append(decls, declaration{SinceName(iface, ev.Name, true), iface.Name + "." + ev.Name + " since constant"})
+	}
+	if len(iface.Events) > 0 {
+		decls = append(decls,
+			declaration{ifn + "ListenerFuncs", iface.Name + " listener funcs"},
+			declaration{ifn + "Event", iface.Name + " event type"})
+		for _, ev := range iface.Events {
+			decls = append(decls, declaration{EventTypeName(iface.Name, ev.Name), iface.Name + "." + ev.Name + " event"})
+		}
+	}
+	return decls
+}
+
+// packageName derives a Go package name from a protocol name, for example
+// xdg_shell becomes xdgshell.
+func packageName(protocol string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, protocol)
+}
+
+// Runtime returns the qualifier for identifiers of the runtime package, or
+// "" when generating the runtime package itself.
+func Runtime() string {
+	if current == nil || current.Path == runtimePath {
+		return ""
+	}
+	return path.Base(runtimePath) + "."
+}
+
+// Qualifier returns the qualifier for the types generated from iface, or
+// "" if they are in the package being generated.
+func Qualifier(iface string) string {
+	pkg, ok := index[iface]
+	if !ok || current == nil || pkg.Path == current.Path {
+		return ""
+	}
+	return pkg.Name + "."
+}
+
+// TypeRef returns the, possibly qualified, Go type name of iface.
+func TypeRef(iface string) string {
+	return Qualifier(iface) + InterfaceName(iface)
+}
+
+// packageImports returns the import paths needed by the generated code for
+// interfaces, failing if they reference an interface that is not indexed.
+func packageImports(interfaces []*Interface) ([]string, error) {
+	imports := make(map[string]bool)
+	if current.Path != runtimePath {
+		imports[runtimePath] = true
+	}
+	refer := func(iface string, from string) error {
+		pkg, ok := index[iface]
+		if !ok {
+			return errors.Errorf("%s references unknown interface %s", from, iface)
+		}
+		if pkg.Path != current.Path {
+			imports[pkg.Path] = true
+		}
+		return nil
+	}
+	for _, iface := range interfaces {
+		if len(iface.Events) > 0 {
+			imports["context"] = true
+		}
+		messages := make(map[string][]*Arg)
+		for _, req := range iface.Requests {
+			messages[iface.Name+"."+req.Name] = req.Args
+		}
+		for _, ev := range iface.Events {
+			messages[iface.Name+"."+ev.Name] = ev.Args
+		}
+		for from, args := range messages {
+			for _, arg := range args {
+				if arg.Type == "fd" {
+					imports["os"] = true
+				}
+				if arg.Interface != "" {
+					if err := refer(arg.Interface, from); err != nil {
+						return nil, err
+					}
+				}
+				if arg.Enum != "" {
+					if err := refer(strings.SplitN(arg.Enum, ".", 2)[0], from); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+	}
+	sorted := make([]string, 0, len(imports))
+	for imp := range imports {
+		sorted = append(sorted, imp)
+	}
+	sort.Strings(sorted)
+	return sorted, nil
+}
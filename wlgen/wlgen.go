@@ -1,135 +1,411 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	_ "embed"
 	"encoding/xml"
+	"flag"
+	"fmt"
 	"github.com/pkg/errors"
 	"github.com/serenize/snaker"
+	"go/format"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"text/template"
-	"bufio"
-	"bytes"
-	"fmt"
 )
 
+//go:embed wl.gotmpl
+var defaultTemplate string
+
+// prefixRule replaces a protocol interface name prefix when forming Go type
+// names, usually with nothing.
+type prefixRule struct {
+	Prefix      string
+	Replacement string
+}
+
+// prefixes are applied to protocol interface names to form Go type names,
+// the longest one that matches is used. Unstable protocols carry an extra
+// z in front of their namespace.
+var prefixes = []prefixRule{
+	{Prefix: "wl_"},
+	{Prefix: "wp_"},
+	{Prefix: "xdg_"},
+	{Prefix: "zwp_"},
+	{Prefix: "zxdg_"},
+}
+
+// versionSuffix matches the version that unstable and staging protocols
+// append to their interface names, such as the _v1 of
+// zwp_linear_dmabuf_v1.
+var versionSuffix = regexp.MustCompile(`_v([0-9]+)$`)
+
+// parsePrefixRule parses a -prefix flag value, either a prefix to strip or
+// prefix=Replacement.
+func parsePrefixRule(value string) (prefixRule, error) {
+	parts := strings.SplitN(value, "=", 2)
+	rule := prefixRule{Prefix: parts[0]}
+	if len(parts) == 2 {
+		rule.Replacement = parts[1]
+	}
+	if rule.Prefix == "" {
+		return rule, errors.Errorf("invalid prefix rule (%s)", value)
+	}
+	return rule, nil
+}
+
 type Description struct {
 	Summary string `xml:"summary,attr"`
-	Text string `xml:",chardata"`
+	Text    string `xml:",chardata"`
 }
 
 type Request struct {
-	Name string `xml:"name,attr"`
-	Type string `xml:"type,attr"`
-	Since string `xml:"since,attr"`
+	Name        string       `xml:"name,attr"`
+	Type        string       `xml:"type,attr"`
+	Since       string       `xml:"since,attr"`
 	Description *Description `xml:"description"`
-	Args []*Arg `xml:"arg"`
+	Args        []*Arg       `xml:"arg"`
 }
 
 type Event struct {
-	Name string `xml:"name,attr"`
-	Since string `xml:"since,attr"`
+	Name        string       `xml:"name,attr"`
+	Since       string       `xml:"since,attr"`
 	Description *Description `xml:"description"`
-	Args []*Arg `xml:"arg"`
+	Args        []*Arg       `xml:"arg"`
 }
 
 type Enum struct {
-	Name string `xml:"name,attr"`
-	Since string `xml:"since,attr"`
-	Bitfield string `xml:"bitfield,attr"`
+	Name        string       `xml:"name,attr"`
+	Since       string       `xml:"since,attr"`
+	Bitfield    string       `xml:"bitfield,attr"`
 	Description *Description `xml:"description"`
-	Entries []*Entry `xml:"entry"`
+	Entries     []*Entry     `xml:"entry"`
 }
 
 type Arg struct {
-	Name string `xml:"name,attr"`
-	Type string `xml:"type,attr"`
-	Summary string `xml:"summary,attr"`
-	Interface string `xml:"interface,attr"`
-	AllowNull string `xml:"allow-null,attr"`
-	Enum string `xml:"enum,attr"`
+	Name        string       `xml:"name,attr"`
+	Type        string       `xml:"type,attr"`
+	Summary     string       `xml:"summary,attr"`
+	Interface   string       `xml:"interface,attr"`
+	AllowNull   string       `xml:"allow-null,attr"`
+	Enum        string       `xml:"enum,attr"`
 	Description *Description `xml:"description"`
+	// withUntypedNewID is set on the arguments of a request that also has
+	// an untyped new_id argument.
+	withUntypedNewID bool
 }
 
 type Entry struct {
-	Name string `xml:"name,attr"`
-	Value string `xml:"value,attr"`
-	Summary string `xml:"summary,attr"`
-	Since string `xml:"since,attr"`
+	Name        string       `xml:"name,attr"`
+	Value       string       `xml:"value,attr"`
+	Summary     string       `xml:"summary,attr"`
+	Since       string       `xml:"since,attr"`
 	Description *Description `xml:"description"`
 }
 
 type Interface struct {
-	Name string`xml:"name,attr"`
-	Version string `xml:"version,attr"`
+	Name        string       `xml:"name,attr"`
+	Version     string       `xml:"version,attr"`
 	Description *Description `xml:"description"`
-	Requests []*Request `xml:"request"`
-	Events []*Event `xml:"event"`
-	Enums []*Enum `xml:"enum"`
+	Requests    []*Request   `xml:"request"`
+	Events      []*Event     `xml:"event"`
+	Enums       []*Enum      `xml:"enum"`
 }
 
 type Protocol struct {
-	Name string `xml:"name,attr"`
-	Copyright string `xml:"copyright"`
+	Name        string       `xml:"name,attr"`
+	Copyright   string       `xml:"copyright"`
 	Description *Description `xml:"description"`
-	Interfaces []*Interface `xml:"interface"`
+	Interfaces  []*Interface `xml:"interface"`
+}
+
+// ParseError reports a protocol file that could not be parsed, with the
+// line the decoder stopped at.
+type ParseError struct {
+	File string
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s:%d: %v", e.File, e.Line, e.Err)
 }
 
 func parse(raw []byte) (*Protocol, error) {
 	p := &Protocol{}
-	err := xml.Unmarshal(raw, p)
-	return p, errors.Wrap(err, "unable to parse xml")
+	d := xml.NewDecoder(bytes.NewReader(raw))
+	err := d.Decode(p)
+	if err != nil {
+		line := bytes.Count(raw[:d.InputOffset()], []byte("\n")) + 1
+		if serr, ok := err.(*xml.SyntaxError); ok {
+			line = serr.Line
+		}
+		return p, &ParseError{Line: line, Err: errors.Wrap(err, "unable to parse xml")}
+	}
+	// qualify enum references local to their interface so arguments can be
+	// resolved without knowing where they were declared
+	for _, iface := range p.Interfaces {
+		args := make([]*Arg, 0)
+		for _, req := range iface.Requests {
+			args = append(args, req.Args...)
+		}
+		for _, ev := range iface.Events {
+			args = append(args, ev.Args...)
+		}
+		for _, arg := range args {
+			if arg.Enum != "" && !strings.Contains(arg.Enum, ".") {
+				arg.Enum = iface.Name + "." + arg.Enum
+			}
+		}
+		for _, req := range iface.Requests {
+			if id := NewIDArg(req.Args); id != nil && id.Interface == "" {
+				for _, arg := range req.Args {
+					arg.withUntypedNewID = true
+				}
+			}
+		}
+	}
+	return p, nil
 }
 
-func genTemplate(templateText string) *template.Template {
-	funcMap := template.FuncMap{
-		"ifname": InterfaceName,
-		"camel": snaker.SnakeToCamel,
-		"desc_to_comment": DescriptionToComment,
-		"req_sig": ReqSignature,
-		"req_ret_sig": ReqReturnSignature,
-		"req_ret": ReqReturn,
+func parseFile(path string) (*Protocol, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read protocol (%s)", path)
+	}
+	p, err := parse(raw)
+	if perr, ok := err.(*ParseError); ok {
+		perr.File = path
 	}
+	return p, err
+}
 
-	return template.Must(template.New("wl").Funcs(funcMap).Parse(templateText))
+// genData is the value the template is executed with.
+type genData struct {
+	Package    string
+	Imports    []string
+	Interfaces []*Interface
+}
 
+// generate renders the interfaces of the protocols making up pkg into a
+// single gofmt'd Go source file. Every interface they reference must have been
+// indexed, see indexProtocol.
+func generate(w io.Writer, tmpl *template.Template, pkg *goPackage, protocols []*Protocol) error {
+	current = pkg
+	defer func() { current = nil }()
+	data := &genData{Package: pkg.Name}
+	for _, p := range protocols {
+		data.Interfaces = append(data.Interfaces, p.Interfaces...)
+	}
+	imports, err := packageImports(data.Interfaces)
+	if err != nil {
+		return err
+	}
+	data.Imports = imports
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, data); err != nil {
+		return errors.Wrap(err, "unable to execute template")
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return errors.Wrap(err, "generated code does not parse")
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+var funcMap = template.FuncMap{
+	"ifname":          InterfaceName,
+	"req_name":        RequestName,
+	"event_name":      EventName,
+	"entry_name":      EntryName,
+	"since":           SinceVersion,
+	"since_name":      SinceName,
+	"desc_to_comment": DescriptionToComment,
+	"req_sig":         ReqSignature,
+	"req_ret_sig":     ReqReturnSignature,
+	"new_id":          NewIDArg,
+	"arg_put":         ArgPut,
+	"arg_name":        ArgName,
+	"arg_get":         ArgGet,
+	"event_sig":       EventSignature,
+	"event_args":      EventArgs,
+	"event_type":      EventTypeName,
+	"handler_name":    HandlerName,
+	"event_field":     EventField,
+	"event_values":    EventFieldValues,
+	"fd_count":        FdCount,
+	"null_check":      NullCheck,
+	"enum_type":       EnumTypeName,
+	"unique_entries":  UniqueEntries,
+	"rt":              Runtime,
+	"typeref":         TypeRef,
 }
 
+func genTemplate(templateText string) *template.Template {
+	return template.Must(template.New("wl").Funcs(funcMap).Parse(templateText))
+}
+
+// InterfaceName returns the Go type name for a protocol interface: the
+// longest matching prefix rule is applied, the rest is camel cased and a
+// version suffix is kept, so zwp_linear_dmabuf_v1 becomes LinearDmabufV1.
 func InterfaceName(name string) string {
-	name = strings.TrimLeft(name, "wl_")
-	return snaker.SnakeToCamel(name)
+	var rule *prefixRule
+	for i := range prefixes {
+		if strings.HasPrefix(name, prefixes[i].Prefix) && (rule == nil || len(prefixes[i].Prefix) > len(rule.Prefix)) {
+			rule = &prefixes[i]
+		}
+	}
+	replacement := ""
+	if rule != nil {
+		name = strings.TrimPrefix(name, rule.Prefix)
+		replacement = rule.Replacement
+	}
+	version := ""
+	if m := versionSuffix.FindStringSubmatch(name); m != nil {
+		name = strings.TrimSuffix(name, m[0])
+		version = "V" + m[1]
+	}
+	return identifier(replacement+snaker.SnakeToCamel(name)+version, "X")
 }
 
 func DescriptionToComment(desc string) string {
 	buf := &bytes.Buffer{}
 	scanner := bufio.NewScanner(strings.NewReader(strings.TrimSpace(desc)))
 	for scanner.Scan() {
-			buf.WriteString("// ")
-			buf.Write(bytes.TrimSpace(scanner.Bytes()))
-			buf.WriteString("\n")
-
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			buf.WriteString("//\n")
+			continue
+		}
+		buf.WriteString("// ")
+		buf.Write(line)
+		buf.WriteString("\n")
 	}
 	return buf.String()
 }
 
 func ArgSignature(arg *Arg) string {
-	name := snaker.SnakeToCamelLower(arg.Name)
-	if name == "interface" {
-		name = "iface"
+	if arg.Type == "new_id" && arg.Interface == "" {
+		// an untyped new_id is sent as the interface name and version
+		// followed by the id itself
+		return fmt.Sprintf("iface *%sInterface, version uint32", Runtime())
+	}
+	typ := ArgType(arg)
+	if typ == "" {
+		return ""
 	}
-	buf := bytes.NewBufferString(name)
-	buf.WriteString(" ")
+	return ArgName(arg) + " " + typ
+}
+
+// ArgType returns the Go type of a request argument, or "" for arguments
+// that have none.
+func ArgType(arg *Arg) string {
 	switch arg.Type {
-	case "int":
-		buf.WriteString("int32")
-	case "uint", "fixed", "object":
-		buf.WriteString("uint32")
+	case "int", "uint":
+		switch {
+		case arg.Enum != "":
+			return EnumType(arg)
+		case arg.Type == "int":
+			return "int32"
+		default:
+			return "uint32"
+		}
+	case "object":
+		return ObjectType(arg)
+	case "fixed":
+		return Runtime() + "Fixed"
 	case "string":
-		buf.WriteString("string")
+		if Nullable(arg) {
+			return "*string"
+		}
+		return "string"
 	case "array":
-		buf.WriteString("[]byte")
+		return "[]byte"
+	case "fd":
+		return "*os.File"
 	default:
 		return ""
 	}
-	return buf.String()
+}
+
+// EnumTypeName returns the Go type generated for an enum of an interface.
+func EnumTypeName(iface string, enum string) string {
+	return InterfaceName(iface) + identifier(snaker.SnakeToCamel(enum), "")
+}
+
+// EnumType returns the Go type of an argument carrying an enum attribute,
+// which parse has qualified with its interface, qualified with its package
+// if it is declared in another one.
+func EnumType(arg *Arg) string {
+	parts := strings.SplitN(arg.Enum, ".", 2)
+	return Qualifier(parts[0]) + EnumTypeName(parts[0], parts[1])
+}
+
+// UniqueEntries returns the entries of an enum skipping any that repeat the
+// value of an earlier one, so they can be used as switch cases.
+func UniqueEntries(entries []*Entry) []*Entry {
+	seen := make(map[string]bool)
+	unique := make([]*Entry, 0)
+	for _, e := range entries {
+		if !seen[e.Value] {
+			seen[e.Value] = true
+			unique = append(unique, e)
+		}
+	}
+	return unique
+}
+
+func Nullable(arg *Arg) bool {
+	return arg.AllowNull == "true"
+}
+
+// NullCheck returns the name of a request parameter that has to be checked
+// for nil before sending, or "" if nil is allowed or cannot be passed.
+func NullCheck(arg *Arg) string {
+	switch arg.Type {
+	case "object":
+		if Nullable(arg) {
+			return ""
+		}
+		return ArgName(arg)
+	case "fd":
+		return ArgName(arg)
+	case "new_id":
+		if arg.Interface == "" {
+			return "iface"
+		}
+	}
+	return ""
+}
+
+// ObjectType returns the Go type of an object argument, falling back to
+// Object when the XML does not name the interface.
+func ObjectType(arg *Arg) string {
+	if arg.Interface == "" {
+		return Runtime() + "Object"
+	}
+	return "*" + TypeRef(arg.Interface)
+}
+
+// SinceVersion returns the interface version a request, event or enum
+// entry was introduced in, which is 1 if the since attribute is missing.
+func SinceVersion(since string) (int, error) {
+	if since == "" {
+		return 1, nil
+	}
+	v, err := strconv.Atoi(since)
+	if err != nil || v < 1 {
+		return 0, errors.Errorf("invalid since version (%s)", since)
+	}
+	return v, nil
 }
 
 func ReqSignature(args []*Arg) string {
@@ -143,34 +419,321 @@ func ReqSignature(args []*Arg) string {
 	return strings.Join(argSigs, ", ")
 }
 
-func ReqReturnSignature(args []*Arg) string {
-	newTypeInterface := ""
+// NewIDArg returns the new_id argument of a request, or nil if the
+// request does not create an object.
+func NewIDArg(args []*Arg) *Arg {
 	for _, arg := range args {
 		if arg.Type == "new_id" {
-			newTypeInterface = arg.Interface
-			break
+			return arg
 		}
 	}
-	if newTypeInterface == "" {
+	return nil
+}
+
+func ReqReturnSignature(args []*Arg) string {
+	arg := NewIDArg(args)
+	if arg == nil {
 		return "error"
 	}
-	return fmt.Sprintf("(*%s, error)", InterfaceName(newTypeInterface))
+	if arg.Interface == "" {
+		return fmt.Sprintf("(%sObject, error)", Runtime())
+	}
+	return fmt.Sprintf("(*%s, error)", TypeRef(arg.Interface))
 }
 
-func ReqReturn(args []*Arg) string {
-	newTypeInterface := ""
+// ArgPut returns the statement marshaling a request argument into msg.
+func ArgPut(arg *Arg) string {
+	name := ArgName(arg)
+	if arg.Enum != "" {
+		return fmt.Sprintf("msg.PutUint(uint32(%s))", name)
+	}
+	switch arg.Type {
+	case "int":
+		return fmt.Sprintf("msg.PutInt(%s)", name)
+	case "uint":
+		return fmt.Sprintf("msg.PutUint(%s)", name)
+	case "object":
+		put := fmt.Sprintf("msg.PutObject(%s.ObjectID)", name)
+		if arg.Interface == "" {
+			put = fmt.Sprintf("msg.PutObject(%sObjectID(%s.ID()))", Runtime(), name)
+		}
+		if Nullable(arg) {
+			return fmt.Sprintf("if %s == nil {\n        msg.PutObject(0)\n    } else {\n        %s\n    }", name, put)
+		}
+		return put
+	case "fixed":
+		return fmt.Sprintf("msg.PutFixed(%s)", name)
+	case "string":
+		if Nullable(arg) {
+			return fmt.Sprintf("msg.PutNullString(%s)", name)
+		}
+		return fmt.Sprintf("msg.PutString(%s)", name)
+	case "array":
+		return fmt.Sprintf("msg.PutArray(%s)", name)
+	case "fd":
+		return fmt.Sprintf("msg.PutFd(%s)", name)
+	case "new_id":
+		if arg.Interface == "" {
+			return "msg.PutString(iface.Name)\n    msg.PutUint(version)\n    msg.PutNewID(ret, version)"
+		}
+		return "msg.PutNewID(ret, this.Proxy.Version())"
+	default:
+		return ""
+	}
+}
+
+// EventArgSignature returns the listener parameter for an event argument.
+// Objects created by the server are passed as the new proxy.
+func EventArgSignature(arg *Arg) string {
+	if arg.Type == "new_id" && arg.Interface != "" {
+		return fmt.Sprintf("%s *%s", ArgName(arg), TypeRef(arg.Interface))
+	}
+	return ArgSignature(arg)
+}
+
+// EventTypeName returns the name of the struct generated for an event of
+// an interface, which implements the event type of the interface.
+func EventTypeName(iface string, event string) string {
+	return InterfaceName(iface) + EventName(event) + "Event"
+}
+
+// EventField returns the struct field holding an event argument, or "" for
+// arguments that have none.
+func EventField(arg *Arg) string {
+	typ := ArgType(arg)
+	if arg.Type == "new_id" && arg.Interface != "" {
+		typ = "*" + TypeRef(arg.Interface)
+	}
+	if typ == "" {
+		return ""
+	}
+	return FieldName(arg) + " " + typ
+}
+
+// EventFieldValues returns the keyed elements of the event struct literal
+// built from the decoded arguments, matching EventField.
+func EventFieldValues(args []*Arg) string {
+	values := make([]string, 0)
 	for _, arg := range args {
-		if arg.Type == "new_id" {
-			newTypeInterface = arg.Interface
-			break
+		if EventField(arg) != "" {
+			values = append(values, FieldName(arg)+": "+ArgName(arg))
+		}
+	}
+	return strings.Join(values, ", ")
+}
+
+func EventSignature(args []*Arg) string {
+	argSigs := make([]string, 0)
+	for _, arg := range args {
+		newSig := EventArgSignature(arg)
+		if newSig != "" {
+			argSigs = append(argSigs, newSig)
 		}
 	}
-	if newTypeInterface == "" {
-		return "nil"
+	return strings.Join(argSigs, ", ")
+}
+
+// EventArgs returns the argument list passed to a listener method, matching
+// EventSignature.
+func EventArgs(args []*Arg) string {
+	names := make([]string, 0)
+	for _, arg := range args {
+		if EventArgSignature(arg) != "" {
+			names = append(names, ArgName(arg))
+		}
 	}
-	return "nil, nil"
+	return strings.Join(names, ", ")
 }
 
-func main() {
+// ArgGet returns the statement decoding an event argument from ev.
+func ArgGet(arg *Arg) string {
+	name := ArgName(arg)
+	if arg.Enum != "" {
+		return fmt.Sprintf("%s := %s(ev.GetUint())", name, EnumType(arg))
+	}
+	switch arg.Type {
+	case "int":
+		return fmt.Sprintf("%s := ev.GetInt()", name)
+	case "uint":
+		return fmt.Sprintf("%s := ev.GetUint()", name)
+	case "object":
+		if arg.Interface == "" {
+			return fmt.Sprintf("%s := ev.GetProxy(this.Proxy.Client())", name)
+		}
+		return fmt.Sprintf("%s, _ := ev.GetProxy(this.Proxy.Client()).(%s)", name, ObjectType(arg))
+	case "fixed":
+		return fmt.Sprintf("%s := ev.GetFixed()", name)
+	case "string":
+		if Nullable(arg) {
+			return fmt.Sprintf("%s := ev.GetNullString()", name)
+		}
+		return fmt.Sprintf("%s := ev.GetString()", name)
+	case "array":
+		return fmt.Sprintf("%s := ev.GetArray()", name)
+	case "fd":
+		return fmt.Sprintf("%s := ev.GetFd()", name)
+	case "new_id":
+		if arg.Interface == "" {
+			return ""
+		}
+		return fmt.Sprintf("%s := &%s{Proxy: %sProxy{ObjectID: ev.GetObject()}}",
+			name, TypeRef(arg.Interface), Runtime())
+	default:
+		return ""
+	}
+}
 
+// FdCount returns the number of file descriptor arguments in a message.
+func FdCount(args []*Arg) int {
+	n := 0
+	for _, arg := range args {
+		if arg.Type == "fd" {
+			n++
+		}
+	}
+	return n
+}
+
+// stringList is a flag that may be repeated or given a comma separated
+// list of values.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, strings.Split(value, ",")...)
+	return nil
+}
+
+func run() error {
+	var inputs stringList
+	var refs stringList
+	var prefixFlag stringList
+	flag.Var(&inputs, "i", "protocol XML file to generate from, may be repeated")
+	flag.Var(&refs, "r", "protocol XML file generated elsewhere, as file=importpath, may be repeated")
+	output := flag.String("o", "", "output file for a single input, standard output if empty, or output directory for several")
+	pkgName := flag.String("pkg", "", "package name for a single input (default derived from the protocol name)")
+	importPath := flag.String("import", runtimePath, "import path of the generated package for a single input, or of the output directory for several")
+	flag.StringVar(&runtimePath, "runtime", runtimePath, "import path of the wl runtime package")
+	flag.Var(&prefixFlag, "prefix", "interface name prefix to strip, or prefix=Replacement, may be repeated (default wl_, wp_, xdg_, zwp_, zxdg_)")
+	templatePath := flag.String("template", "", "template to use instead of the built in one")
+	check := flag.Bool("check", false, "compare the output files with freshly generated code instead of writing them")
+	flag.Parse()
+
+	if len(inputs) == 0 {
+		return errors.New("no input files, use -i")
+	}
+	if len(inputs) > 1 && (*output == "" || *pkgName != "") {
+		return errors.New("several inputs need an output directory with -o and cannot use -pkg")
+	}
+	if *check && *output == "" {
+		return errors.New("-check needs the output to compare with, use -o")
+	}
+	if len(prefixFlag) > 0 {
+		prefixes = nil
+		for _, value := range prefixFlag {
+			rule, err := parsePrefixRule(value)
+			if err != nil {
+				return err
+			}
+			prefixes = append(prefixes, rule)
+		}
+	}
+	templateText := defaultTemplate
+	if *templatePath != "" {
+		raw, err := ioutil.ReadFile(*templatePath)
+		if err != nil {
+			return errors.Wrapf(err, "unable to read template (%s)", *templatePath)
+		}
+		templateText = string(raw)
+	}
+	tmpl, err := template.New("wl").Funcs(funcMap).Parse(templateText)
+	if err != nil {
+		return errors.Wrap(err, "unable to parse template")
+	}
+
+	for _, ref := range refs {
+		parts := strings.SplitN(ref, "=", 2)
+		if len(parts) != 2 {
+			return errors.Errorf("invalid reference (%s), expected file=importpath", ref)
+		}
+		p, err := parseFile(parts[0])
+		if err != nil {
+			return err
+		}
+		if err := indexProtocol(p, &goPackage{Name: path.Base(parts[1]), Path: parts[1]}); err != nil {
+			return err
+		}
+	}
+
+	protocols := make([]*Protocol, len(inputs))
+	pkgs := make([]*goPackage, len(inputs))
+	for i, input := range inputs {
+		p, err := parseFile(input)
+		if err != nil {
+			return err
+		}
+		pkg := &goPackage{Name: packageName(p.Name), Path: *importPath}
+		if len(inputs) > 1 {
+			pkg.Path = path.Join(*importPath, pkg.Name)
+		} else if *pkgName != "" {
+			pkg.Name = *pkgName
+		}
+		if err := indexProtocol(p, pkg); err != nil {
+			return err
+		}
+		protocols[i] = p
+		pkgs[i] = pkg
+	}
+
+	for i, p := range protocols {
+		buf := &bytes.Buffer{}
+		if err := generate(buf, tmpl, pkgs[i], []*Protocol{p}); err != nil {
+			return errors.Wrapf(err, "unable to generate %s", inputs[i])
+		}
+		out := *output
+		if len(inputs) > 1 {
+			out = filepath.Join(*output, pkgs[i].Name, "protocol.go")
+		}
+		if out == "" {
+			if _, err := os.Stdout.Write(buf.Bytes()); err != nil {
+				return err
+			}
+			continue
+		}
+		if *check {
+			if err := checkOutput(out, buf.Bytes()); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(out), 0755); err != nil {
+			return errors.Wrapf(err, "unable to create output directory (%s)", filepath.Dir(out))
+		}
+		if err := ioutil.WriteFile(out, buf.Bytes(), 0644); err != nil {
+			return errors.Wrapf(err, "unable to write output (%s)", out)
+		}
+	}
+	return nil
+}
+
+// checkOutput fails if the file at out does not hold exactly src.
+func checkOutput(out string, src []byte) error {
+	existing, err := ioutil.ReadFile(out)
+	if err != nil {
+		return errors.Wrapf(err, "unable to read output (%s)", out)
+	}
+	if !bytes.Equal(existing, src) {
+		return errors.Errorf("%s is out of date, regenerate it with go generate", out)
+	}
+	return nil
+}
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "wlgen:", err)
+		os.Exit(1)
+	}
 }
@@ -1,10 +1,19 @@
 package main
 
 import (
-	"testing"
-	"io/ioutil"
+	"bytes"
 	"github.com/stretchr/testify/assert"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
+	"testing"
 )
 
 func TestParse(t *testing.T) {
@@ -40,7 +49,287 @@ func TestGenTemplate(t *testing.T) {
 	tmplText, err := ioutil.ReadFile("wl.gotmpl")
 	assert.NoError(t, err)
 	tmpl := genTemplate(string(tmplText))
-	f, err := os.Create("../protocol.go")
+	index = make(map[string]*goPackage)
+	pkg := &goPackage{Name: "wl", Path: runtimePath}
+	assert.NoError(t, indexProtocol(p, pkg))
+	buf := &bytes.Buffer{}
+	assert.NoError(t, generate(buf, tmpl, pkg, []*Protocol{p}))
+	assert.True(t, strings.HasPrefix(buf.String(), "package wl\n"))
+	assert.Contains(t, buf.String(), "type Display struct")
+	formatted, err := format.Source(buf.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, string(formatted), buf.String(), "generated code is not gofmt'd")
+	again := &bytes.Buffer{}
+	assert.NoError(t, generate(again, tmpl, pkg, []*Protocol{p}))
+	assert.Equal(t, buf.String(), again.String(), "generated code is not deterministic")
+}
+
+func TestGenInvalidCode(t *testing.T) {
+	index = make(map[string]*goPackage)
+	pkg := &goPackage{Name: "wl", Path: runtimePath}
+	err := generate(&bytes.Buffer{}, genTemplate("package {{.Package}}\nfunc {"), pkg, nil)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "generated code does not parse")
+	}
+}
+
+func TestDescriptionToComment(t *testing.T) {
+	desc := "\n      First line.  \n\n      Second line.\t\n    "
+	assert.Equal(t, "// First line.\n//\n// Second line.\n", DescriptionToComment(desc))
+}
+
+func TestCheckOutput(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wlgen")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "protocol.go")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("package wl\n"), 0644))
+	assert.NoError(t, checkOutput(path, []byte("package wl\n")))
+	assert.Error(t, checkOutput(path, []byte("package wl\n\nconst x = 1\n")))
+	assert.Error(t, checkOutput(filepath.Join(dir, "missing.go"), []byte("package wl\n")))
+}
+
+func TestParseError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wlgen")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "broken.xml")
+	raw := "<protocol name=\"broken\">\n  <interface name=\"wl_broken\" version=\"1\">\n    <request name=\"oops\">\n  </interface>\n</protocol>\n"
+	assert.NoError(t, ioutil.WriteFile(path, []byte(raw), 0644))
+	_, err = parseFile(path)
+	perr, ok := err.(*ParseError)
+	if assert.True(t, ok, "unexpected error %v", err) {
+		assert.Equal(t, 4, perr.Line)
+		assert.True(t, strings.HasPrefix(perr.Error(), path+":4: "))
+	}
+}
+
+const extensionXML = `<protocol name="test_extension">
+  <interface name="test_manager" version="1">
+    <request name="get_thing">
+      <arg name="id" type="new_id" interface="test_thing"/>
+      <arg name="surface" type="object" interface="wl_surface"/>
+    </request>
+  </interface>
+  <interface name="test_thing" version="1">
+    <event name="entered">
+      <arg name="output" type="object" interface="wl_output" allow-null="true"/>
+      <arg name="transform" type="uint" enum="wl_output.transform"/>
+    </event>
+  </interface>
+</protocol>
+`
+
+func TestGenCrossReference(t *testing.T) {
+	data, err := ioutil.ReadFile("wayland.xml")
+	assert.NoError(t, err)
+	core, err := parse(data)
+	assert.NoError(t, err)
+	ext, err := parse([]byte(extensionXML))
+	assert.NoError(t, err)
+
+	index = make(map[string]*goPackage)
+	assert.NoError(t, indexProtocol(core, &goPackage{Name: "wl", Path: runtimePath}))
+	pkg := &goPackage{Name: packageName(ext.Name), Path: "example.com/protocols/testextension"}
+	assert.Equal(t, "testextension", pkg.Name)
+	assert.NoError(t, indexProtocol(ext, pkg))
+	assert.Error(t, indexProtocol(ext, pkg), "duplicate interfaces must be rejected")
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, generate(buf, genTemplate(defaultTemplate), pkg, []*Protocol{ext}))
+	out := buf.String()
+	assert.True(t, strings.HasPrefix(out, "package testextension\n"))
+	assert.Contains(t, out, "\""+runtimePath+"\"")
+	assert.NotContains(t, out, "\"os\"")
+	assert.Contains(t, out, "wl.Proxy\n")
+	assert.Contains(t, out, "surface *wl.Surface")
+	assert.Contains(t, out, "output *wl.Output")
+	assert.Contains(t, out, "wl.OutputTransform")
+	assert.Contains(t, out, "ret := &TestThing{}")
+}
+
+func TestGenUnknownReference(t *testing.T) {
+	ext, err := parse([]byte(extensionXML))
+	assert.NoError(t, err)
+	index = make(map[string]*goPackage)
+	pkg := &goPackage{Name: "testextension", Path: "example.com/protocols/testextension"}
+	assert.NoError(t, indexProtocol(ext, pkg))
+	err = generate(&bytes.Buffer{}, genTemplate(defaultTemplate), pkg, []*Protocol{ext})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "unknown interface wl_surface")
+	}
+}
+
+func TestInterfaceName(t *testing.T) {
+	names := map[string]string{
+		"wl_surface":             "Surface",
+		"wl_data_device":         "DataDevice",
+		"xdg_wm_base":            "WmBase",
+		"zxdg_output_v1":         "OutputV1",
+		"zwp_linear_dmabuf_v1":   "LinearDmabufV1",
+		"wp_viewporter":          "Viewporter",
+		"wlr_layer_shell_v1":     "WlrLayerShellV1",
+		"lowercase_with_wl":      "LowercaseWithWl",
+		"zwp_tablet_seat_v2":     "TabletSeatV2",
+		"wp_fractional_scale_v1": "FractionalScaleV1",
+	}
+	for name, expected := range names {
+		assert.Equal(t, expected, InterfaceName(name), name)
+	}
+
+	defer func(saved []prefixRule) { prefixes = saved }(prefixes)
+	rule, err := parsePrefixRule("zxdg_=Xdg")
+	assert.NoError(t, err)
+	prefixes = []prefixRule{rule}
+	assert.Equal(t, "XdgOutputV1", InterfaceName("zxdg_output_v1"))
+	_, err = parsePrefixRule("=Xdg")
+	assert.Error(t, err)
+}
+
+func TestInterfaceNameCollision(t *testing.T) {
+	p, err := parse([]byte(`<protocol name="clash">
+  <interface name="wl_thing" version="1"/>
+  <interface name="xdg_thing" version="1"/>
+</protocol>`))
+	assert.NoError(t, err)
+	index = make(map[string]*goPackage)
+	err = indexProtocol(p, &goPackage{Name: "clash", Path: "example.com/clash"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "wl_thing and xdg_thing both map to Thing")
+	}
+}
+
+func TestEventTypeNameCollision(t *testing.T) {
+	p, err := parse([]byte(`<protocol name="clash">
+  <interface name="wl_thing" version="1">
+    <event name="done"/>
+  </interface>
+  <interface name="wl_thing_done" version="1">
+    <event name="ready"/>
+  </interface>
+</protocol>`))
+	assert.NoError(t, err)
+	index = make(map[string]*goPackage)
+	err = indexProtocol(p, &goPackage{Name: "clash", Path: "example.com/clash"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "both map to ThingDoneEvent")
+	}
+}
+
+func TestDeclarationNameCollision(t *testing.T) {
+	tests := []struct {
+		xml  string
+		want string
+	}{
+		{`<interface name="r_thing" version="1">
+    <enum name="kind"><entry name="a" value="0"/></enum>
+  </interface>
+  <interface name="r_thing_kind" version="1"/>`, "r_thing_kind and r_thing.kind enum both map to RThingKind"},
+		{`<interface name="wl_thing" version="1"/>
+  <interface name="wl_thing_listener" version="1"/>`, "wl_thing_listener and wl_thing listener both map to ThingListener"},
+		{`<interface name="wl_thing" version="1">
+    <request name="go"/>
+  </interface>
+  <interface name="wl_thing_go_since_version" version="1"/>`, "both map to ThingGoSinceVersion"},
+	}
+	for _, test := range tests {
+		p, err := parse([]byte(`<protocol name="clash">` + test.xml + `</protocol>`))
+		assert.NoError(t, err)
+		index = make(map[string]*goPackage)
+		err = indexProtocol(p, &goPackage{Name: "clash", Path: "example.com/clash"})
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), test.want)
+		}
+	}
+}
+
+const reservedXML = `<protocol name="reserved">
+  <interface name="func" version="2">
+    <request name="range">
+      <arg name="type" type="uint" enum="type"/>
+      <arg name="func" type="string"/>
+      <arg name="map" type="object" interface="select"/>
+      <arg name="string" type="string" allow-null="true"/>
+      <arg name="msg" type="int"/>
+      <arg name="this" type="fixed"/>
+      <arg name="os" type="fd"/>
+      <arg name="wl" type="array"/>
+      <arg name="interface" type="object" interface="wl_surface"/>
+    </request>
+    <request name="interface"/>
+    <request name="dispatch"/>
+    <request name="go">
+      <arg name="ret" type="new_id" interface="select"/>
+      <arg name="return" type="uint" enum="const"/>
+    </request>
+    <request name="default" type="destructor"/>
+    <event name="default">
+      <arg name="chan" type="uint" enum="type"/>
+      <arg name="ev" type="int"/>
+      <arg name="error" type="string"/>
+      <arg name="nil" type="object"/>
+      <arg name="len" type="array"/>
+      <arg name="os" type="fd"/>
+      <arg name="new" type="new_id" interface="select"/>
+    </event>
+    <enum name="type">
+      <entry name="90" value="0"/>
+      <entry name="import" value="1"/>
+      <entry name="2d" value="2"/>
+    </enum>
+    <enum name="const" bitfield="true">
+      <entry name="var" value="1"/>
+      <entry name="1x" value="2"/>
+    </enum>
+    <enum name="error">
+      <entry name="return" value="0"/>
+    </enum>
+  </interface>
+  <interface name="select" version="1">
+    <event name="case">
+      <arg name="goto" type="uint" enum="func.type"/>
+    </event>
+    <event name="on_case">
+      <arg name="this" type="int"/>
+      <arg name="ok" type="int"/>
+    </event>
+    <request name="bind">
+      <arg name="version" type="uint"/>
+      <arg name="id" type="new_id"/>
+      <arg name="iface" type="string"/>
+    </request>
+  </interface>
+  <interface name="3d_thing" version="1">
+    <request name="break">
+      <arg name="continue" type="object" interface="3d_thing"/>
+    </request>
+  </interface>
+</protocol>
+`
+
+func TestGenReservedWords(t *testing.T) {
+	data, err := ioutil.ReadFile("wayland.xml")
 	assert.NoError(t, err)
-	tmpl.Execute(f, p)
-}
\ No newline at end of file
+	core, err := parse(data)
+	assert.NoError(t, err)
+	p, err := parse([]byte(reservedXML))
+	assert.NoError(t, err)
+
+	index = make(map[string]*goPackage)
+	assert.NoError(t, indexProtocol(core, &goPackage{Name: "wl", Path: runtimePath}))
+	pkg := &goPackage{Name: "reserved", Path: "example.com/reserved"}
+	assert.NoError(t, indexProtocol(p, pkg))
+	buf := &bytes.Buffer{}
+	if !assert.NoError(t, generate(buf, genTemplate(defaultTemplate), pkg, []*Protocol{p})) {
+		return
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "protocol.go", buf.Bytes(), 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check(pkg.Path, fset, []*ast.File{file}, nil)
+	assert.NoError(t, err, "generated code does not type check:\n%s", buf.String())
+}